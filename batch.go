@@ -0,0 +1,287 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// number of output lines shown for a failed row
+const batchExcerptLines = 3
+
+// BatchRow is one project of a batch file. The header of the file names the
+// questions, e.g. customerName, plus dir for the target directory.
+type BatchRow struct {
+	line    int
+	dir     string
+	answers Answers
+}
+
+type BatchResult struct {
+	row      BatchRow
+	err      error
+	duration time.Duration
+	excerpt  string
+}
+
+func readBatchFile(path string) ([]BatchRow, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return parseBatchRows(file)
+}
+
+func parseBatchRows(input io.Reader) ([]BatchRow, error) {
+	records, err := readCsv(input)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]string, len(records[0]))
+	for i, key := range records[0] {
+		header[i] = strings.ToLower(strings.TrimSpace(key))
+		if header[i] != "" && header[i] != "dir" && !isQuestionKey(header[i]) {
+			return nil, fmt.Errorf("column %s is no question", strings.TrimSpace(key))
+		}
+	}
+
+	rows := make([]BatchRow, 0, len(records)-1)
+	dirs := make(map[string]int)
+	for i, record := range records[1:] {
+		row := BatchRow{line: i + 2, answers: Answers{}}
+		for column, value := range record {
+			value = strings.TrimSpace(value)
+			if column >= len(header) || header[column] == "" || value == "" {
+				continue
+			}
+			if header[column] == "dir" {
+				row.dir = value
+			} else {
+				row.answers[header[column]] = value
+			}
+		}
+		if row.dir == "" {
+			row.dir = row.answers["internalprojectname"]
+		}
+		if row.dir == "" {
+			return nil, fmt.Errorf("line %d: neither dir nor internalProjectName given", row.line)
+		}
+		absolute, err := filepath.Abs(row.dir)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", row.line, err)
+		}
+		if previous, found := dirs[absolute]; found {
+			return nil, fmt.Errorf("line %d: directory %s is already used on line %d", row.line, row.dir, previous)
+		}
+		dirs[absolute] = row.line
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// isQuestionKey checks whether a lower case key answers a wizard question.
+func isQuestionKey(key string) bool {
+	for _, question := range wizardQuestions {
+		if strings.ToLower(question.Id) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// batchCmdArgs passes the global settings on to the non-interactive run
+// creating one row's project.
+func batchCmdArgs(row BatchRow) []string {
+	cmdArgs := []string{
+		"-dir", row.dir,
+		"-username", args.username,
+		"-config", args.config,
+		"-profile", args.profile,
+		"-cmd-timeout", args.cmdTimeout.String(),
+		"-http-timeout", args.httpTimeout.String(),
+		"-non-interactive",
+		"-logfile",
+		"-quiet",
+	}
+	if args.debug {
+		cmdArgs = append(cmdArgs, "-debug")
+	}
+	if args.gradleVersion != "" {
+		cmdArgs = append(cmdArgs, "-gradle-version", args.gradleVersion)
+	}
+
+	keys := make([]string, 0, len(row.answers))
+	for key := range row.answers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		cmdArgs = append(cmdArgs, "-set", key+"="+row.answers[key])
+	}
+	return cmdArgs
+}
+
+// runBatch creates the projects of a CSV file, -workers at a time. Credentials
+// are verified once and handed to every run, so nobody is asked again.
+func runBatch(ctx context.Context) {
+	if len(args.commandArgs) != 1 {
+		log.Fatalf("Usage: solutionist batch projects.csv")
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		log.Fatalf("Could not find the solutionist executable: %s", err)
+	}
+
+	checkEnvironment()
+	loginToHelga(ctx)
+	checkNexusCredentials()
+
+	// the columns are checked against the questions of the template
+	loadQuestions(ctx)
+	rows, err := readBatchFile(args.commandArgs[0])
+	if err != nil {
+		log.Fatalf("Could not read %s: %s", args.commandArgs[0], err)
+	}
+
+	workers := args.workers
+	if workers < 1 {
+		workers = 1
+	}
+	log.Info("")
+	log.Info("> Creating %d projects, %d at a time", len(rows), workers)
+
+	results := make([]BatchResult, len(rows))
+	for index, row := range rows {
+		// rows left over after an interrupt keep this result
+		results[index] = BatchResult{row: row, err: fmt.Errorf("not started")}
+	}
+	var resultsMutex sync.Mutex
+	var reportOnce sync.Once
+	allPassed := false
+	printReport := func() {
+		reportOnce.Do(func() {
+			resultsMutex.Lock()
+			defer resultsMutex.Unlock()
+			allPassed = printBatchReport(results)
+		})
+	}
+	// an interrupt ends the program, the report is shown anyway
+	removeCleanup := addCleanup(printReport)
+
+	jobs := make(chan int)
+	var done sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			for index := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				runningCmds.Add(1)
+				result := runBatchRow(ctx, executable, rows[index])
+				resultsMutex.Lock()
+				results[index] = result
+				resultsMutex.Unlock()
+				runningCmds.Done()
+
+				if result.err != nil {
+					log.Error("Line %d: %s failed after %s", rows[index].line, rows[index].dir, result.duration.Round(time.Second))
+				} else {
+					log.Notice("Line %d: %s created in %s", rows[index].line, rows[index].dir, result.duration.Round(time.Second))
+				}
+			}
+		}()
+	}
+	for index := range rows {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- index
+	}
+	close(jobs)
+	done.Wait()
+
+	if ctx.Err() != nil {
+		// prints the report and exits like the interrupt handler does
+		abort()
+	}
+	removeCleanup()
+	printReport()
+	if !allPassed {
+		os.Exit(1)
+	}
+}
+
+// runBatchRow creates the project of a row in a child process, which is
+// interrupted like gradle when ctx is cancelled.
+func runBatchRow(ctx context.Context, executable string, row BatchRow) BatchResult {
+	if err := os.MkdirAll(row.dir, 0777); err != nil {
+		return BatchResult{row: row, err: err, excerpt: err.Error()}
+	}
+
+	cmd := exec.CommandContext(ctx, executable, batchCmdArgs(row)...)
+	stopOnCancel(cmd)
+	cmd.Env = append(os.Environ(), "SOLUTIONIST_PASSWORD="+args.password)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	log.Debug("> Executing: %s %s", executable, strings.Join(batchCmdArgs(row), " "))
+	start := time.Now()
+	err := cmd.Run()
+	result := BatchResult{row: row, err: err, duration: time.Since(start)}
+	if err != nil {
+		result.excerpt = lastLines(output.String(), batchExcerptLines)
+	}
+	return result
+}
+
+func lastLines(output string, count int) string {
+	lines := make([]string, 0)
+	for _, line := range strings.Split(strings.Replace(output, "\r\n", "\n", -1), "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > count {
+		lines = lines[len(lines)-count:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func printBatchReport(results []BatchResult) bool {
+	log.Info("")
+	allPassed := true
+	for _, result := range results {
+		if result.err == nil {
+			log.Notice("%4d  %-40s OK", result.row.line, result.row.dir)
+		} else {
+			allPassed = false
+			log.Error("%4d  %-40s FAILED  %s", result.row.line, result.row.dir, result.err)
+			for _, line := range strings.Split(result.excerpt, "\n") {
+				if line == "" {
+					continue
+				}
+				log.Warning("%4s  %-40s -> %s", "", "", line)
+			}
+		}
+	}
+	log.Info("")
+	if allPassed {
+		log.Notice("All %d projects created.", len(results))
+	} else {
+		log.Error("Not all projects were created, see solutionist.log in their directories.")
+	}
+	return allPassed
+}
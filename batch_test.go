@@ -0,0 +1,42 @@
+package main
+
+import (
+	. "github.com/franela/goblin"
+	. "github.com/onsi/gomega"
+	"strings"
+	"testing"
+)
+
+func TestBatch(t *testing.T) {
+	g := Goblin(t)
+
+	RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	g.Describe("Reading a batch file", func() {
+		g.It("Should turn columns into answers", func() {
+			rows, err := parseBatchRows(strings.NewReader("dir;customerName;internalProjectName;version\nacme;ACME;acme_portal;\n;Foo;foo_forms;1.0.0\n"))
+			Expect(err).Should(BeNil())
+			Expect(len(rows)).Should(Equal(2))
+			Expect(rows[0].dir).Should(Equal("acme"))
+			Expect(rows[0].answers).Should(Equal(Answers{"customername": "ACME", "internalprojectname": "acme_portal"}))
+			Expect(rows[1].line).Should(Equal(3))
+			Expect(rows[1].dir).Should(Equal("foo_forms"))
+		})
+		g.It("Should refuse columns which are no question", func() {
+			_, err := parseBatchRows(strings.NewReader("dir,customerName,customerNmae\nacme,ACME,ACME\n"))
+			Expect(err).ShouldNot(BeNil())
+			Expect(err.Error()).Should(ContainSubstring("customerNmae"))
+		})
+		g.It("Should refuse rows sharing a directory", func() {
+			_, err := parseBatchRows(strings.NewReader("dir,customerName\nacme,ACME\n./acme,Other\n"))
+			Expect(err).ShouldNot(BeNil())
+		})
+	})
+
+	g.Describe("Answering questions", func() {
+		g.It("Should use the heading of a question as key", func() {
+			Expect(questionKey("\nCUSTOMERNAME:\nFull name of the customer\n")).Should(Equal("customername"))
+			Expect(questionKey("Continue anyway? (y/n)")).Should(Equal(""))
+		})
+	})
+}
@@ -5,25 +5,89 @@ import (
 	"fmt"
 	"os"
 	"os/user"
+	"sort"
 	"strings"
+	"time"
 )
 
+// commands besides the default one, which creates a new project
+var commands = map[string]string{
+	"batch":  "Creates one project per row of a CSV file, e.g. batch projects.csv",
+	"doctor": "Checks environment, tools, credentials and connectivity",
+	"list":   "Lists the projects you created, e.g. list acme group=customer",
+	"login":  "Verifies and stores your Helga credentials encrypted",
+	"logout": "Removes stored Helga credentials",
+	"show":   "Shows a project you created by Helga name, artifact id or directory",
+}
+
 type CmdlineArgs struct {
-	dir      string
-	username string
-	password string
-	logfile  bool
-	debug    bool
-	color    bool
+	command        string
+	commandArgs    []string
+	dir            string
+	username       string
+	usernameSet    bool
+	password       string
+	passwordStdin  bool
+	logfile        bool
+	debug          bool
+	quiet          bool
+	color          bool
+	config         string
+	profile        string
+	gradleVersion  string
+	cmdTimeout     time.Duration
+	httpTimeout    time.Duration
+	nonInteractive bool
+	answers        Answers
+	workers        int
+	output         string
+}
+
+// Answers are given with -set key=value and replace the questions of the
+// wizard in non-interactive mode. Keys are case-insensitive.
+type Answers map[string]string
+
+func (a Answers) String() string {
+	keys := make([]string, 0, len(a))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+a[key])
+	}
+	return strings.Join(pairs, " ")
+}
+
+func (a Answers) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+		return fmt.Errorf("expected key=value but got %q", value)
+	}
+	a[strings.ToLower(strings.TrimSpace(parts[0]))] = parts[1]
+	return nil
 }
 
 func (a CmdlineArgs) String() string {
-	args := fmt.Sprintf("dir=%s\n", a.dir)
+	args := fmt.Sprintf("command=%s %s\n", a.command, strings.Join(a.commandArgs, " "))
+	args += fmt.Sprintf("dir=%s\n", a.dir)
 	args += fmt.Sprintf("username=%s\n", a.username)
 	args += fmt.Sprintf("password=%s\n", a.maskedPassword())
+	args += fmt.Sprintf("password-stdin=%v\n", a.passwordStdin)
 	args += fmt.Sprintf("logfile=%v\n", a.logfile)
 	args += fmt.Sprintf("debug=%v\n", a.debug)
+	args += fmt.Sprintf("quiet=%v\n", a.quiet)
 	args += fmt.Sprintf("color=%v\n", a.color)
+	args += fmt.Sprintf("config=%s\n", a.config)
+	args += fmt.Sprintf("profile=%s\n", a.profile)
+	args += fmt.Sprintf("gradle-version=%s\n", a.gradleVersion)
+	args += fmt.Sprintf("cmd-timeout=%s\n", a.cmdTimeout)
+	args += fmt.Sprintf("http-timeout=%s\n", a.httpTimeout)
+	args += fmt.Sprintf("non-interactive=%v\n", a.nonInteractive)
+	args += fmt.Sprintf("set=%s\n", a.answers)
+	args += fmt.Sprintf("workers=%d\n", a.workers)
+	args += fmt.Sprintf("output=%s\n", a.output)
 	return args
 }
 
@@ -42,15 +106,72 @@ func parseCmdline() CmdlineArgs {
 	dir := flag.String("dir", ".", "Target directory to create project in; defaults to current directory")
 	username := flag.String("username", defaultUsername, "Username used for authentication")
 	password := flag.String("password", "", "Password used for authentication")
+	passwordStdin := flag.Bool("password-stdin", false, "Reads the password from the first line of stdin")
 	logfile := flag.Bool("logfile", false, "Logs output to logile in project directory")
 	debug := flag.Bool("debug", false, "Show debug information")
+	quiet := flag.Bool("quiet", false, "Show output of external commands only if they fail")
 	color := flag.Bool("color", false, "Use colors in output. Uses ANSI escape sequences")
-	flag.Parse()
+	config := flag.String("config", defaultConfigPath(), "Config file containing profiles")
+	profile := flag.String("profile", "default", "Profile from the config file to use")
+	gradleVersion := flag.String("gradle-version", "", "Gradle version of the generated wrapper; defaults to the profile, the template or the local Gradle")
+	cmdTimeout := flag.Duration("cmd-timeout", 30*time.Minute, "Maximum duration of a gradle or hg invocation, 0 for none")
+	httpTimeout := flag.Duration("http-timeout", 60*time.Second, "Maximum duration of a request to Helga or Nexus, 0 for none")
+	nonInteractive := flag.Bool("non-interactive", false, "Never ask; questions are answered with -set or their defaults, confirmations with no")
+	answers := Answers{}
+	flag.Var(answers, "set", "Answer for a question in non-interactive mode, e.g. -set customerName=ACME; may be repeated")
+	workers := flag.Int("workers", 4, "Number of projects the batch command creates at the same time")
+	output := flag.String("output", "", "Set to json to print a summary of the run to stdout; the log then goes to stderr")
+	flag.Usage = usage
+
+	// the command may be given before or after the flags
+	arguments := os.Args[1:]
+	command := ""
+	if len(arguments) > 0 && !strings.HasPrefix(arguments[0], "-") {
+		command = arguments[0]
+		arguments = arguments[1:]
+	}
+	flag.CommandLine.Parse(arguments)
+	commandArgs := flag.Args()
+	if command == "" && len(commandArgs) > 0 {
+		command = commandArgs[0]
+		commandArgs = commandArgs[1:]
+	}
+	usernameSet := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "username" {
+			usernameSet = true
+		}
+	})
+	if _, found := commands[command]; command != "" && !found {
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", command)
+		usage()
+		os.Exit(2)
+	}
+
+	if *output != "" && *output != "json" {
+		fmt.Fprintf(os.Stderr, "Unknown output format: %s\n", *output)
+		os.Exit(2)
+	}
 
 	err = os.MkdirAll(*dir, 0777)
 	if err != nil {
 		log.Fatal("Target directory could not be created: %s", err)
 	}
 
-	return CmdlineArgs{dir: *dir, username: *username, password: *password, logfile: *logfile, debug: *debug, color: *color}
+	return CmdlineArgs{command: command, commandArgs: commandArgs, dir: *dir, username: *username, usernameSet: usernameSet, password: *password, passwordStdin: *passwordStdin, logfile: *logfile, debug: *debug, quiet: *quiet, color: *color, config: *config, profile: *profile, gradleVersion: *gradleVersion, cmdTimeout: *cmdTimeout, httpTimeout: *httpTimeout, nonInteractive: *nonInteractive, answers: answers, workers: *workers, output: *output}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [command] [flags]\n\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "Without a command a new project is created.\n\nCommands:\n")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name])
+	}
+	fmt.Fprintf(os.Stderr, "\nFlags:\n")
+	flag.PrintDefaults()
 }
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	defaultHelgaUrl = "http://helga/scm/"
+	defaultNexusUrl = "http://nexus/nexus/content/groups/public/"
+)
+
+// Profile holds settings that differ between kinds of users or teams,
+// e.g. consultancy and product development.
+type Profile struct {
+	IgnorePatterns         []string       `json:"ignorePatterns"`
+	ReadmeTemplate         string         `json:"readmeTemplate"`
+	ChangelogTemplate      string         `json:"changelogTemplate"`
+	HelgaUrl               string         `json:"helgaUrl"`
+	NexusUrl               string         `json:"nexusUrl"`
+	CaBundle               string         `json:"caBundle"`
+	TasArtifact            string         `json:"tasArtifact"`
+	SolutionPluginArtifact string         `json:"solutionPluginArtifact"`
+	NexusUsernameKey       string         `json:"nexusUsernameKey"`
+	NexusPasswordKey       string         `json:"nexusPasswordKey"`
+	HttpRetries            *int           `json:"httpRetries"`
+	GradleVersion          string         `json:"gradleVersion"`
+	VerifyTask             string         `json:"verifyTask"`
+	CustomerDirectory      string         `json:"customerDirectory"`
+	WorkspaceRoot          string         `json:"workspaceRoot"`
+	QuestionsFile          string         `json:"questionsFile"`
+	TasJavaVersions        map[string]int `json:"tasJavaVersions"`
+}
+
+// Config is read from the JSON file given with -config.
+type Config struct {
+	Profiles map[string]Profile `json:"profiles"`
+}
+
+func userHomeDir() string {
+	currentUser, err := user.Current()
+	if err != nil {
+		return "."
+	}
+	return currentUser.HomeDir
+}
+
+// solutionistHomeDir holds files Solutionist keeps between runs.
+func solutionistHomeDir() string {
+	return filepath.Join(userHomeDir(), ".solutionist")
+}
+
+func defaultConfigPath() string {
+	return filepath.Join(userHomeDir(), ".solutionist.json")
+}
+
+func loadProfile() {
+	profile = readProfile()
+	if profile.HelgaUrl == "" {
+		profile.HelgaUrl = defaultHelgaUrl
+	}
+	if !strings.HasSuffix(profile.HelgaUrl, "/") {
+		profile.HelgaUrl += "/"
+	}
+	if profile.NexusUrl == "" {
+		profile.NexusUrl = defaultNexusUrl
+	}
+	if profile.TasArtifact == "" {
+		profile.TasArtifact = defaultTasArtifact
+	}
+	if profile.SolutionPluginArtifact == "" {
+		profile.SolutionPluginArtifact = defaultSolutionPluginArtifact
+	}
+	if profile.NexusUsernameKey == "" {
+		profile.NexusUsernameKey = defaultNexusUsernameKey
+	}
+	if profile.NexusPasswordKey == "" {
+		profile.NexusPasswordKey = defaultNexusPasswordKey
+	}
+	if profile.VerifyTask == "" {
+		profile.VerifyTask = "tasks"
+	}
+}
+
+// retries of idempotent requests; HttpRetries is a pointer so 0 can be configured
+func (p Profile) httpRetries() int {
+	if p.HttpRetries == nil {
+		return defaultHttpRetries
+	}
+	return *p.HttpRetries
+}
+
+// the JDK per TAS version range of the profile, or the built-in ranges
+func (p Profile) tasJavaVersions() map[string]int {
+	if len(p.TasJavaVersions) == 0 {
+		return defaultTasJavaVersions
+	}
+	return p.TasJavaVersions
+}
+
+func readProfile() Profile {
+	if args.config == "" {
+		return Profile{}
+	}
+
+	input, err := ioutil.ReadFile(args.config)
+	if os.IsNotExist(err) {
+		log.Debug("No config file found at [%s]", args.config)
+		return Profile{}
+	}
+	if err != nil {
+		log.Fatalf("Could not read config file %s: %s", args.config, err)
+	}
+
+	var config Config
+	if err := json.Unmarshal(input, &config); err != nil {
+		log.Fatalf("Could not parse config file %s: %s", args.config, err)
+	}
+
+	selected, found := config.Profiles[args.profile]
+	if !found {
+		log.Warning("Profile [%s] not found in %s, using defaults", args.profile, args.config)
+		return Profile{}
+	}
+	log.Debug("Using profile [%s] from %s", args.profile, args.config)
+	return selected
+}
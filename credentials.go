@@ -0,0 +1,299 @@
+package main
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Credentials are stored encrypted in the Solutionist home directory. The key
+// lives in a separate file only readable by the user, so the credentials file
+// alone, e.g. in a backup, does not reveal the password.
+type Credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+func credentialsPath() string {
+	return filepath.Join(solutionistHomeDir(), "credentials")
+}
+
+func credentialsKeyPath() string {
+	return filepath.Join(solutionistHomeDir(), "credentials.key")
+}
+
+func netrcPath() string {
+	name := ".netrc"
+	if runtime.GOOS == "windows" {
+		name = "_netrc"
+	}
+	return filepath.Join(userHomeDir(), name)
+}
+
+func helgaHost() string {
+	parsed, err := url.Parse(profile.HelgaUrl)
+	if err != nil {
+		return ""
+	}
+	return parsed.Hostname()
+}
+
+// requestCredentials looks for the password in this order: -password,
+// SOLUTIONIST_PASSWORD, -password-stdin, ~/.netrc, the stored credentials.
+// If none is found the user is asked.
+func requestCredentials() {
+	if args.password == "" {
+		args.password = os.Getenv("SOLUTIONIST_PASSWORD")
+		if args.password != "" {
+			log.Debug("Using password from SOLUTIONIST_PASSWORD")
+		}
+	}
+
+	if args.password == "" && args.passwordStdin {
+		password, err := readLineFromStdin()
+		if err != nil {
+			log.Fatalf("Could not read password from stdin: %s", err)
+		}
+		args.password = password
+		log.Debug("Using password from stdin")
+	}
+
+	if args.password == "" {
+		if credentials, found := lookupNetrc(helgaHost()); found && (!args.usernameSet || credentials.Username == args.username) {
+			args.username = credentials.Username
+			args.password = credentials.Password
+			log.Debug("Using credentials from %s", netrcPath())
+		}
+	}
+
+	if args.password == "" {
+		if credentials, err := loadCredentials(); err == nil && (!args.usernameSet || credentials.Username == args.username) {
+			args.username = credentials.Username
+			args.password = credentials.Password
+			log.Debug("Using credentials stored with 'solutionist login'")
+		}
+	}
+
+	if args.username == "" {
+		requestInput(&args.username, "Username needed:")
+	}
+
+	if args.password == "" {
+		requestHiddenInput(&args.password, "Password needed:")
+	}
+}
+
+// readLineFromStdin reads byte by byte so no input meant for later prompts
+// is swallowed by a buffer.
+func readLineFromStdin() (string, error) {
+	line := make([]byte, 0)
+	buffer := make([]byte, 1)
+	for {
+		n, err := os.Stdin.Read(buffer)
+		if n > 0 {
+			if buffer[0] == '\n' {
+				break
+			}
+			line = append(line, buffer[0])
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimRight(string(line), "\r"), nil
+}
+
+// parseNetrc returns the login and password for host, falling back to the
+// default entry. Macro definitions are skipped.
+func parseNetrc(content string, host string) (Credentials, bool) {
+	tokens := strings.Fields(content)
+	var current, fallback *Credentials
+	var matched Credentials
+	found := false
+
+	for i := 0; i < len(tokens); i++ {
+		next := ""
+		if i+1 < len(tokens) {
+			next = tokens[i+1]
+		}
+		switch tokens[i] {
+		case "machine":
+			current = nil
+			if next == host {
+				current = &matched
+				found = true
+			}
+			i++
+		case "default":
+			fallback = &Credentials{}
+			current = fallback
+		case "login":
+			if current != nil {
+				current.Username = next
+			}
+			i++
+		case "password":
+			if current != nil {
+				current.Password = next
+			}
+			i++
+		case "macdef":
+			// a macro runs until an empty line, which Fields cannot see; stop here
+			return pickNetrcEntry(matched, found, fallback)
+		}
+	}
+	return pickNetrcEntry(matched, found, fallback)
+}
+
+func pickNetrcEntry(matched Credentials, found bool, fallback *Credentials) (Credentials, bool) {
+	if found {
+		return matched, matched.Password != ""
+	}
+	if fallback != nil {
+		return *fallback, fallback.Password != ""
+	}
+	return Credentials{}, false
+}
+
+func lookupNetrc(host string) (Credentials, bool) {
+	input, err := ioutil.ReadFile(netrcPath())
+	if err != nil {
+		return Credentials{}, false
+	}
+	return parseNetrc(string(input), host)
+}
+
+func loadCredentialsKey(create bool) ([]byte, error) {
+	key, err := ioutil.ReadFile(credentialsKeyPath())
+	if err == nil && len(key) == 32 {
+		return key, nil
+	}
+	if !create {
+		return nil, fmt.Errorf("no key found")
+	}
+
+	key = make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(solutionistHomeDir(), 0700); err != nil {
+		return nil, err
+	}
+	return key, ioutil.WriteFile(credentialsKeyPath(), key, 0600)
+}
+
+func encryptCredentials(credentials Credentials, key []byte) (string, error) {
+	plaintext, err := json.Marshal(credentials)
+	if err != nil {
+		return "", err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, plaintext, nil)), nil
+}
+
+func decryptCredentials(encoded string, key []byte) (Credentials, error) {
+	var credentials Credentials
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return credentials, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return credentials, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return credentials, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return credentials, fmt.Errorf("credentials file is corrupt")
+	}
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return credentials, fmt.Errorf("credentials file is corrupt: %s", err)
+	}
+	err = json.Unmarshal(plaintext, &credentials)
+	return credentials, err
+}
+
+func loadCredentials() (Credentials, error) {
+	encoded, err := ioutil.ReadFile(credentialsPath())
+	if err != nil {
+		return Credentials{}, err
+	}
+	key, err := loadCredentialsKey(false)
+	if err != nil {
+		return Credentials{}, err
+	}
+	return decryptCredentials(string(encoded), key)
+}
+
+func storeCredentials(credentials Credentials) error {
+	key, err := loadCredentialsKey(true)
+	if err != nil {
+		return err
+	}
+	encoded, err := encryptCredentials(credentials, key)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(credentialsPath(), []byte(encoded+"\n"), 0600)
+}
+
+func runLogin(ctx context.Context) {
+	if !args.usernameSet {
+		requestInput(&args.username, "Username needed:")
+	}
+	if args.password == "" && os.Getenv("SOLUTIONIST_PASSWORD") == "" && !args.passwordStdin {
+		// the stored credentials are about to be replaced, so they are not used
+		requestHiddenInput(&args.password, "Password needed:")
+	}
+	loginToHelga(ctx)
+
+	if err := storeCredentials(Credentials{Username: args.username, Password: args.password}); err != nil {
+		log.Fatalf("Could not store credentials: %s", err)
+	}
+	log.Notice("Logged in as %s, credentials stored in %s", args.username, credentialsPath())
+}
+
+func runLogout() {
+	removed := false
+	for _, path := range []string{credentialsPath(), credentialsKeyPath()} {
+		err := os.Remove(path)
+		if err == nil {
+			removed = true
+		} else if !os.IsNotExist(err) {
+			log.Fatalf("Could not remove %s: %s", path, err)
+		}
+	}
+	if removed {
+		log.Notice("Stored credentials removed")
+	} else {
+		log.Notice("No stored credentials found")
+	}
+}
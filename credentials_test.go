@@ -0,0 +1,53 @@
+package main
+
+import (
+	. "github.com/franela/goblin"
+	. "github.com/onsi/gomega"
+	"testing"
+)
+
+func TestCredentials(t *testing.T) {
+	g := Goblin(t)
+
+	RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	g.Describe("Reading a netrc file", func() {
+		netrc := "machine nexus login deployer password secret\n" +
+			"machine helga\n  login chuckn\n  password iamchucknorris\n" +
+			"default login anonymous password guest\n"
+
+		g.It("Should find the entry of the host", func() {
+			credentials, found := parseNetrc(netrc, "helga")
+			Expect(found).Should(BeTrue())
+			Expect(credentials).Should(Equal(Credentials{Username: "chuckn", Password: "iamchucknorris"}))
+		})
+		g.It("Should fall back to the default entry", func() {
+			credentials, found := parseNetrc(netrc, "unknown")
+			Expect(found).Should(BeTrue())
+			Expect(credentials.Username).Should(Equal("anonymous"))
+		})
+		g.It("Should not find anything without matching or default entry", func() {
+			_, found := parseNetrc("machine nexus login a password b", "helga")
+			Expect(found).Should(BeFalse())
+		})
+	})
+
+	g.Describe("Encrypting credentials", func() {
+		key := []byte("0123456789abcdef0123456789abcdef")
+
+		g.It("Should decrypt what was encrypted", func() {
+			encoded, err := encryptCredentials(Credentials{"chuckn", "iamchucknorris"}, key)
+			Expect(err).Should(BeNil())
+			Expect(encoded).ShouldNot(ContainSubstring("iamchucknorris"))
+
+			credentials, err := decryptCredentials(encoded, key)
+			Expect(err).Should(BeNil())
+			Expect(credentials.Password).Should(Equal("iamchucknorris"))
+		})
+		g.It("Should fail with another key", func() {
+			encoded, _ := encryptCredentials(Credentials{"chuckn", "iamchucknorris"}, key)
+			_, err := decryptCredentials(encoded, []byte("fedcba9876543210fedcba9876543210"))
+			Expect(err).ShouldNot(BeNil())
+		})
+	})
+}
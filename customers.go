@@ -0,0 +1,381 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maximum number of customers offered after a search
+const maxCustomerMatches = 10
+
+type Customer struct {
+	ReferenceNumber string `json:"referenceNumber"`
+	Name            string `json:"name"`
+}
+
+// CustomerDirectory knows the canonical customer names, e.g. from TOPhelp.
+type CustomerDirectory interface {
+	lookup(ctx context.Context, referenceNumber string) (Customer, bool, error)
+	search(ctx context.Context, name string) ([]Customer, error)
+}
+
+var (
+	slugPattern            = regexp.MustCompile(`[^a-z0-9]+`)
+	referenceNumberPattern = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// slugLetters spells out the lowercase letters with diacritics found in
+// European customer names.
+var slugLetters = strings.NewReplacer(
+	"à", "a", "á", "a", "â", "a", "ã", "a", "ä", "a", "å", "a", "æ", "ae",
+	"ç", "c", "č", "c", "ć", "c",
+	"è", "e", "é", "e", "ê", "e", "ë", "e", "ě", "e", "ę", "e",
+	"ì", "i", "í", "i", "î", "i", "ï", "i", "ĳ", "ij",
+	"ł", "l", "ñ", "n", "ń", "n", "ň", "n",
+	"ò", "o", "ó", "o", "ô", "o", "õ", "o", "ö", "o", "ø", "o", "œ", "oe",
+	"ř", "r", "š", "s", "ś", "s", "ß", "ss", "ť", "t",
+	"ù", "u", "ú", "u", "û", "u", "ü", "u", "ů", "u",
+	"ý", "y", "ÿ", "y", "ž", "z", "ź", "z", "ż", "z",
+)
+
+// slug turns a name into the form used for Helga paths and artifact ids,
+// e.g. "Gemeente Den Haag" becomes "gemeente-den-haag" and "Zürich" "zurich".
+func slug(name string) string {
+	return strings.Trim(slugPattern.ReplaceAllString(slugLetters.Replace(strings.ToLower(name)), "-"), "-")
+}
+
+// newCustomerDirectory returns nil if no directory is configured. URLs are
+// queried as REST endpoint, anything else is read as CSV export.
+func newCustomerDirectory(location string) CustomerDirectory {
+	if location == "" {
+		return nil
+	}
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		return &restCustomerDirectory{url: location}
+	}
+	return &csvCustomerDirectory{path: location}
+}
+
+// csvCustomerDirectory reads a TOPhelp export. Columns are found by their
+// header.
+type csvCustomerDirectory struct {
+	path      string
+	customers []Customer
+}
+
+func (d *csvCustomerDirectory) load() error {
+	if d.customers != nil {
+		return nil
+	}
+	file, err := os.Open(d.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	customers, err := parseCustomerCsv(file)
+	if err != nil {
+		return fmt.Errorf("could not read %s: %s", d.path, err)
+	}
+	d.customers = customers
+	return nil
+}
+
+func parseCustomerCsv(input io.Reader) ([]Customer, error) {
+	records, err := readCsv(input)
+	if err != nil {
+		return nil, err
+	}
+
+	numberColumn, nameColumn := -1, -1
+	for i, header := range records[0] {
+		switch strings.ToLower(strings.TrimSpace(header)) {
+		case "reference number", "referencenumber", "customer reference number", "number", "nummer":
+			numberColumn = i
+		case "name", "customer name", "customer", "naam":
+			nameColumn = i
+		}
+	}
+	if numberColumn < 0 || nameColumn < 0 {
+		return nil, fmt.Errorf("columns for reference number and name not found in header %v", records[0])
+	}
+
+	customers := make([]Customer, 0, len(records)-1)
+	for _, record := range records[1:] {
+		if numberColumn >= len(record) || nameColumn >= len(record) {
+			continue
+		}
+		customers = append(customers, Customer{
+			ReferenceNumber: strings.TrimSpace(record[numberColumn]),
+			Name:            strings.TrimSpace(record[nameColumn]),
+		})
+	}
+	return customers, nil
+}
+
+func (d *csvCustomerDirectory) lookup(ctx context.Context, referenceNumber string) (Customer, bool, error) {
+	if err := d.load(); err != nil {
+		return Customer{}, false, err
+	}
+	for _, customer := range d.customers {
+		if customer.ReferenceNumber == referenceNumber {
+			return customer, true, nil
+		}
+	}
+	return Customer{}, false, nil
+}
+
+func (d *csvCustomerDirectory) search(ctx context.Context, name string) ([]Customer, error) {
+	if err := d.load(); err != nil {
+		return nil, err
+	}
+	return fuzzySearchCustomers(d.customers, name), nil
+}
+
+// restCustomerDirectory expects an endpoint which answers
+// ?referenceNumber=... and ?name=... with a JSON array of customers.
+type restCustomerDirectory struct {
+	url string
+}
+
+func (d *restCustomerDirectory) query(ctx context.Context, key string, value string) ([]Customer, error) {
+	separator := "?"
+	if strings.Contains(d.url, "?") {
+		separator = "&"
+	}
+	username, password := customerDirectoryCredentials(d.url)
+	input, err := readFromUrl(ctx, d.url+separator+key+"="+url.QueryEscape(value), username, password)
+	if err != nil {
+		return nil, err
+	}
+	var customers []Customer
+	err = json.Unmarshal(input, &customers)
+	return customers, err
+}
+
+// customerDirectoryCredentials hands the Helga credentials only to an
+// endpoint on Helga's own host, never to a different server.
+func customerDirectoryCredentials(directoryUrl string) (string, string) {
+	if sameOrigin(directoryUrl, profile.HelgaUrl) {
+		return args.username, args.password
+	}
+	return "", ""
+}
+
+func sameOrigin(first string, second string) bool {
+	firstUrl, err := url.Parse(first)
+	if err != nil || firstUrl.Host == "" {
+		return false
+	}
+	secondUrl, err := url.Parse(second)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(firstUrl.Scheme, secondUrl.Scheme) && strings.EqualFold(firstUrl.Host, secondUrl.Host)
+}
+
+func (d *restCustomerDirectory) lookup(ctx context.Context, referenceNumber string) (Customer, bool, error) {
+	customers, err := d.query(ctx, "referenceNumber", referenceNumber)
+	if isNotFound(err) {
+		return Customer{}, false, nil
+	}
+	if err != nil {
+		return Customer{}, false, err
+	}
+	for _, customer := range customers {
+		if customer.ReferenceNumber == referenceNumber {
+			return customer, true, nil
+		}
+	}
+	return Customer{}, false, nil
+}
+
+func (d *restCustomerDirectory) search(ctx context.Context, name string) ([]Customer, error) {
+	customers, err := d.query(ctx, "name", name)
+	if err != nil {
+		return nil, err
+	}
+	// the endpoint may not rank its results, so they are ranked here as well
+	return fuzzySearchCustomers(customers, name), nil
+}
+
+// fuzzySearchCustomers ranks customers whose name contains the query first,
+// followed by names whose words start with the query's words and finally
+// names within a small edit distance.
+func fuzzySearchCustomers(customers []Customer, query string) []Customer {
+	type match struct {
+		customer Customer
+		score    int
+	}
+
+	query = strings.ToLower(strings.TrimSpace(query))
+	matches := make([]match, 0)
+	for _, customer := range customers {
+		name := strings.ToLower(customer.Name)
+		score := -1
+		switch {
+		case strings.Contains(name, query):
+			score = 0
+		case wordsStartWith(strings.Fields(name), strings.Fields(query)):
+			score = 1
+		default:
+			distance := levenshtein(name, query)
+			for _, word := range strings.Fields(name) {
+				distance = minInt(distance, levenshtein(word, query))
+			}
+			if distance <= len(query)/3 {
+				score = 2 + distance
+			}
+		}
+		if score >= 0 {
+			matches = append(matches, match{customer, score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score < matches[j].score })
+	result := make([]Customer, 0)
+	for i := 0; i < len(matches) && i < maxCustomerMatches; i++ {
+		result = append(result, matches[i].customer)
+	}
+	return result
+}
+
+func wordsStartWith(words []string, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return false
+	}
+	for _, prefix := range prefixes {
+		found := false
+		for _, word := range words {
+			if strings.HasPrefix(word, prefix) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func levenshtein(a string, b string) int {
+	runesA, runesB := []rune(a), []rune(b)
+	previous := make([]int, len(runesB)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(runesA); i++ {
+		current := make([]int, len(runesB)+1)
+		current[0] = i
+		for j := 1; j <= len(runesB); j++ {
+			cost := 1
+			if runesA[i-1] == runesB[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, minInt(current[j-1]+1, previous[j-1]+cost))
+		}
+		previous = current
+	}
+	return previous[len(runesB)]
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// selectCustomer asks for a reference number or part of the name and fills
+// in customerName and customerReferenceNumber from the customer directory.
+func selectCustomer(ctx context.Context) {
+	directory := newCustomerDirectory(profile.CustomerDirectory)
+	if directory == nil {
+		return
+	}
+
+	query := gradle.customerReferenceNumber
+	requestInput(&query, `
+CUSTOMER:
+Reference number or name of the customer, used to look up the name as registered in TOPhelp.
+Enter - to skip.
+    `)
+	query = strings.TrimSpace(query)
+	if query == "" || query == "-" {
+		return
+	}
+
+	if referenceNumberPattern.MatchString(query) {
+		customer, found, err := directory.lookup(ctx, query)
+		if err != nil {
+			log.Warning("Could not look up customer: %s", err)
+			return
+		}
+		if found {
+			applyCustomer(customer)
+			return
+		}
+		log.Warning("No customer with reference number %s found, searching by name instead", query)
+	}
+
+	customers, err := directory.search(ctx, query)
+	if err != nil {
+		log.Warning("Could not search customers: %s", err)
+		return
+	}
+	if len(customers) == 0 {
+		log.Warning("No customer matching [%s] found", query)
+		return
+	}
+
+	description := "\nMatching customers:\n"
+	for i, customer := range customers {
+		description += fmt.Sprintf(" %2d) %s %s\n", i+1, customer.ReferenceNumber, customer.Name)
+	}
+	choice := "1"
+	requestInput(&choice, description+"Enter the number of the customer, or 0 to skip.")
+	index, err := strconv.Atoi(strings.TrimSpace(choice))
+	if err != nil || index < 1 || index > len(customers) {
+		return
+	}
+	applyCustomer(customers[index-1])
+}
+
+func applyCustomer(customer Customer) {
+	log.Notice("Customer: %s %s", customer.ReferenceNumber, customer.Name)
+	gradle.customerName = customer.Name
+	gradle.customerReferenceNumber = customer.ReferenceNumber
+}
+
+// suggestHelgaName follows the naming conventions for Helga repositories,
+// based on the group of the project.
+func suggestHelgaName() string {
+	switch gradle.group {
+	case "com.topdesk.solution.customer":
+		if gradle.customerReferenceNumber == "" {
+			return ""
+		}
+		return "customers/" + gradle.customerReferenceNumber + "_" + slug(gradle.customerName) + "/" + slug(gradle.projectFullName)
+	case "com.topdesk.solution.addon":
+		return "add-ons/" + slug(gradle.projectFullName)
+	case "com.topdesk.solution.prototype":
+		return "prototypes/" + slug(gradle.projectFullName)
+	case "com.topdesk.solution.tool":
+		return "tools/" + gradle.internalProjectName
+	case "com.topdesk.solution.lib":
+		return "resources/" + gradle.internalProjectName
+	case "com.topdesk.solution.event":
+		return "events/" + gradle.internalProjectName
+	case "com.topdesk.solution.product":
+		return "products/" + gradle.internalProjectName
+	}
+	return ""
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	. "github.com/franela/goblin"
+	. "github.com/onsi/gomega"
+	"strings"
+	"testing"
+)
+
+func TestCustomers(t *testing.T) {
+	g := Goblin(t)
+
+	RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	customers := []Customer{
+		{"1001", "Gemeente Den Haag"},
+		{"1002", "Hogeschool Utrecht"},
+		{"1003", "Gemeente Delft"},
+	}
+
+	g.Describe("Reading a TOPhelp export", func() {
+		g.It("Should find the columns by their header", func() {
+			parsed, err := parseCustomerCsv(strings.NewReader("Status;Name;Reference number\nactive;Gemeente Den Haag;1001\n"))
+			Expect(err).Should(BeNil())
+			Expect(parsed).Should(Equal([]Customer{{"1001", "Gemeente Den Haag"}}))
+		})
+		g.It("Should fail without the required columns", func() {
+			_, err := parseCustomerCsv(strings.NewReader("Status,Name\nactive,Gemeente Den Haag\n"))
+			Expect(err).ShouldNot(BeNil())
+		})
+	})
+
+	g.Describe("Querying a customer directory", func() {
+		var savedArgs CmdlineArgs
+		var savedProfile Profile
+		g.Before(func() {
+			savedArgs, savedProfile = args, profile
+			args.username, args.password = "chuckn", "secret"
+			profile.HelgaUrl = "https://helga.example.com/scm/"
+		})
+		g.It("Should send the Helga credentials to Helga's host only", func() {
+			username, password := customerDirectoryCredentials("https://HELGA.example.com/customers")
+			Expect(username).Should(Equal("chuckn"))
+			Expect(password).Should(Equal("secret"))
+		})
+		g.It("Should not send them to other hosts", func() {
+			for _, directoryUrl := range []string{"https://customers.example.com/api", "http://helga.example.com/customers", "https://helga.example.com:8443/customers", "customers.csv"} {
+				username, password := customerDirectoryCredentials(directoryUrl)
+				Expect(username).Should(Equal(""))
+				Expect(password).Should(Equal(""))
+			}
+		})
+		g.After(func() {
+			args, profile = savedArgs, savedProfile
+		})
+	})
+
+	g.Describe("Searching customers", func() {
+		g.It("Should rank substring matches first", func() {
+			Expect(fuzzySearchCustomers(customers, "gemeente")).Should(Equal([]Customer{customers[0], customers[2]}))
+			Expect(fuzzySearchCustomers(customers, "hog utr")).Should(Equal([]Customer{customers[1]}))
+		})
+		g.It("Should tolerate typos", func() {
+			Expect(fuzzySearchCustomers(customers, "utrect")).Should(Equal([]Customer{customers[1]}))
+			Expect(fuzzySearchCustomers(customers, "rotterdam")).Should(BeEmpty())
+		})
+	})
+
+	g.Describe("Slugs", func() {
+		g.It("Should only contain lowercase letters, digits and dashes", func() {
+			Expect(slug(" Gemeente Den Haag (GDH) ")).Should(Equal("gemeente-den-haag-gdh"))
+		})
+		g.It("Should spell out letters with diacritics", func() {
+			Expect(slug("Coöperatie Zürich")).Should(Equal("cooperatie-zurich"))
+			Expect(slug("École Française")).Should(Equal("ecole-francaise"))
+			Expect(slug("Straße")).Should(Equal("strasse"))
+		})
+	})
+}
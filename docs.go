@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+)
+
+const defaultReadmeTemplate = `# ${projectFullName}
+
+${description}
+
+| | |
+|---|---|
+| Customer | ${customerName} |
+| Customer reference number | ${customerReferenceNumber} |
+| Project | ${internalProjectName} |
+| TAS version | ${tasVersion} |
+| Repository | ${helgaUrl} |
+
+## Build
+
+This project uses the Gradle solution plugin, see
+https://topwiki.topdesk.com/wiki/Gradle_Solution_Plugin
+
+    hg clone ${helgaUrl}
+    gradlew build
+
+The solution zip ends up in build/distributions.
+`
+
+const defaultChangelogTemplate = `# Changes
+
+## ${version}
+Initial version
+`
+
+func projectDocValues() map[string]string {
+	values := gradleConfigValues()
+	values["helgaName"] = helga.Name
+	values["helgaUrl"] = helgaRepoUrl()
+	values["contact"] = helga.Contact
+	return values
+}
+
+// loadDocTemplate prefers a local template from the profile, then one shipped
+// with the build template and falls back to the built-in one.
+func loadDocTemplate(ctx context.Context, name string, localPath string, builtin string) string {
+	if localPath != "" {
+		input, err := ioutil.ReadFile(localPath)
+		if err == nil {
+			log.Debug("Using %s template from %s", name, localPath)
+			return string(input)
+		}
+		log.Warning("Could not read %s template %s: %s", name, localPath, err)
+	}
+
+	input, err := readFromUrl(ctx, templateUrl()+"template-"+name, args.username, args.password)
+	if err == nil {
+		log.Debug("Using %s template shipped with the build template", name)
+		return string(input)
+	}
+	log.Debug("No %s template shipped with the build template (%s), using built-in one", name, err)
+	return builtin
+}
+
+func writeProjectDocs(ctx context.Context) {
+	log.Info("")
+	log.Info("> Writing README.md and CHANGELOG.md")
+
+	values := projectDocValues()
+	writeProjectDoc("README.md", loadDocTemplate(ctx, "README.md", profile.ReadmeTemplate, defaultReadmeTemplate), values)
+	writeProjectDoc("CHANGELOG.md", loadDocTemplate(ctx, "CHANGELOG.md", profile.ChangelogTemplate, defaultChangelogTemplate), values)
+}
+
+func writeProjectDoc(fileName string, template string, values map[string]string) {
+	path := args.dir + "/" + fileName
+	if _, err := os.Stat(path); err == nil {
+		log.Notice("%s already exists, skipping", fileName)
+		return
+	}
+	if err := ioutil.WriteFile(path, []byte(replacePlaceholders(template, values)), 0644); err != nil {
+		log.Critical("Could not write to %s: %s", fileName, err)
+	} else {
+		log.Notice("%s created", fileName)
+	}
+}
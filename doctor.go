@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// DoctorCheck is a single line of the doctor's report. Failed optional
+// checks are only warnings.
+type DoctorCheck struct {
+	name     string
+	passed   bool
+	detail   string
+	hint     string
+	optional bool
+}
+
+var hgVersionPattern = regexp.MustCompile(`\(version ([^)+]+)`)
+
+func runDoctor(ctx context.Context) {
+	log.Info("")
+	log.Info("> Examining your setup:")
+
+	requestCredentials()
+
+	checks := make([]DoctorCheck, 0)
+	checks = append(checks, doctorEnvChecks()...)
+	checks = append(checks, doctorToolCheck("gradle", gradleVersionPattern, "Install Gradle and add GRADLE_HOME/bin to PATH"))
+	checks = append(checks, doctorToolCheck("hg", hgVersionPattern, "Install Mercurial and add it to PATH"))
+	checks = append(checks, doctorHelgaCheck(ctx))
+	checks = append(checks, doctorUrlCheck(ctx, "Template", templateUrl()+"template-build.gradle", "Check that Helga is reachable and you may read gradle/solution-plugin"))
+	checks = append(checks, doctorUrlCheck(ctx, "Nexus", profile.NexusUrl, "Check the nexusUrl in your profile and your network/proxy settings"))
+	checks = append(checks, doctorNexusCredentialsCheck())
+
+	if !printDoctorReport(checks) {
+		os.Exit(1)
+	}
+}
+
+func doctorEnvChecks() []DoctorCheck {
+	checks := make([]DoctorCheck, 0)
+	for _, key := range []string{"JAVA_HOME", "JAVA_HOME_6", "JAVA_HOME_7", "JAVA_HOME_8"} {
+		java := detectJava(key)
+		// only the JDK of the TAS version at hand is needed
+		check := DoctorCheck{name: key, passed: java.err == nil, hint: "Run solutionist to have it discovered, or set " + key + " to a JDK", optional: key != "JAVA_HOME"}
+		if java.err != nil {
+			check.detail = java.err.Error()
+		} else if expected := expectedJavaMajor(key); expected != 0 && expected != java.major {
+			check.passed = false
+			check.detail = fmt.Sprintf("Java %s, expected Java %d", java.version, expected)
+		} else {
+			check.detail = "Java " + java.version
+		}
+		checks = append(checks, check)
+	}
+
+	gradleHome := os.Getenv("GRADLE_HOME")
+	_, err := os.Stat(gradleExecutable(gradleHome))
+	checks = append(checks, DoctorCheck{"GRADLE_HOME", gradleHome != "" && err == nil, gradleHome, "Set GRADLE_HOME to a Gradle distribution", false})
+
+	gradleUserHome := os.Getenv("GRADLE_USER_HOME")
+	checks = append(checks, DoctorCheck{"GRADLE_USER_HOME", gradleUserHome != "", gradleUserHome, "Set GRADLE_USER_HOME, usually to ~/.gradle", false})
+	return checks
+}
+
+func doctorToolCheck(name string, pattern *regexp.Regexp, hint string) DoctorCheck {
+	toolVersion, err := detectToolVersion(name, pattern, "--version")
+	if err != nil {
+		return DoctorCheck{name, false, err.Error(), hint, false}
+	}
+	return DoctorCheck{name, true, "version " + toolVersion, hint, false}
+}
+
+func doctorHelgaCheck(ctx context.Context) DoctorCheck {
+	check := DoctorCheck{name: "Helga login", hint: "Check username and password; use -username to override the guessed username"}
+	if err := authenticateHelga(ctx, args.username, args.password); err != nil {
+		check.detail = err.Error()
+	} else {
+		check.passed = true
+		check.detail = "logged in as " + args.username
+	}
+	return check
+}
+
+func doctorUrlCheck(ctx context.Context, name string, url string, hint string) DoctorCheck {
+	check := DoctorCheck{name: name, hint: hint}
+	if _, err := readFromUrl(ctx, url, args.username, args.password); err != nil {
+		check.detail = fmt.Sprintf("%s (%s)", url, err)
+	} else {
+		check.passed = true
+		check.detail = url
+	}
+	return check
+}
+
+func doctorNexusCredentialsCheck() DoctorCheck {
+	path := filepath.Join(gradleUserHome(), "gradle.properties")
+	check := DoctorCheck{name: "Nexus credentials", detail: path, hint: "Run solutionist to have " + profile.NexusUsernameKey + " and " + profile.NexusPasswordKey + " added"}
+	properties, err := readProperties(path)
+	if err != nil {
+		check.detail = err.Error()
+		return check
+	}
+	_, hasUsername := properties.get(profile.NexusUsernameKey)
+	_, hasPassword := properties.get(profile.NexusPasswordKey)
+	check.passed = hasUsername && hasPassword
+	return check
+}
+
+// printDoctorReport returns false if any required check failed.
+func printDoctorReport(checks []DoctorCheck) bool {
+	log.Info("")
+	allPassed := true
+	for _, check := range checks {
+		if check.passed {
+			log.Notice("%-18s PASS  %s", check.name, check.detail)
+		} else if check.optional {
+			log.Warning("%-18s WARN  %s", check.name, check.detail)
+			log.Warning("%-18s       -> %s", "", check.hint)
+		} else {
+			allPassed = false
+			log.Error("%-18s FAIL  %s", check.name, check.detail)
+			log.Warning("%-18s       -> %s", "", check.hint)
+		}
+	}
+	log.Info("")
+	if allPassed {
+		log.Notice("Everything looks fine.")
+	} else {
+		log.Error("Some checks failed, see the hints above.")
+	}
+	return allPassed
+}
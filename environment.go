@@ -7,13 +7,13 @@ import (
 func checkEnvironment() {
 	log.Info("")
 	log.Info("> Checking environment:")
-	checkEnvVar("JAVA_HOME")
-	checkEnvVar("JAVA_HOME_6")
-	checkEnvVar("JAVA_HOME_7")
-	checkEnvVar("JAVA_HOME_8")
+	checkJava("JAVA_HOME")
+	checkJava("JAVA_HOME_6")
+	checkJava("JAVA_HOME_7")
+	checkJava("JAVA_HOME_8")
 	checkEnvVar("GRADLE_HOME")
 	checkEnvVar("GRADLE_USER_HOME")
-	log.Debug("TODO: Offer to fix that up if necessary")
+	repairEnvironment()
 }
 
 func checkEnvVar(key string) {
@@ -1,11 +1,21 @@
 package main
 
 import (
-	"github.com/nu7hatch/gouuid"
+	"context"
+	"fmt"
 	"io/ioutil"
 	"strings"
 )
 
+// templateUrl points at the revision resolved by resolveTemplateRevision, or tip.
+func templateUrl() string {
+	revision := templateRevision
+	if revision == "" {
+		revision = "tip"
+	}
+	return helgaHgUrl() + "gradle/solution-plugin/raw-file/" + revision + "/setup/"
+}
+
 type GradleConfig struct {
 	version                 string
 	group                   string
@@ -19,130 +29,73 @@ type GradleConfig struct {
 	customerReferenceNumber string
 	uniqueId                string
 	projectType             string
+	// properties defined by questions.json which Solutionist does not know
+	extra map[string]string
 }
 
-func downloadGradleBuildTemplate() {
-	log.Info("")
-	log.Info("> Downloading Gradle build template to directory [%s]", args.dir)
+func (c *GradleConfig) fields() map[string]*string {
+	return map[string]*string{
+		"version":                 &c.version,
+		"group":                   &c.group,
+		"description":             &c.description,
+		"internalProjectName":     &c.internalProjectName,
+		"customerName":            &c.customerName,
+		"projectFullName":         &c.projectFullName,
+		"tasVersion":              &c.tasVersion,
+		"isXfgProject":            &c.isXfgProject,
+		"testCase":                &c.testCase,
+		"customerReferenceNumber": &c.customerReferenceNumber,
+		"uniqueId":                &c.uniqueId,
+		"projectType":             &c.projectType,
+	}
+}
 
-	if args.username == "" {
-		requestInput(&args.username, "Username needed:")
+func (c *GradleConfig) get(key string) string {
+	if field, found := c.fields()[key]; found {
+		return *field
 	}
+	return c.extra[key]
+}
 
-	if args.password == "" {
-		requestHiddenInput(&args.password, "Password needed:")
+func (c *GradleConfig) set(key string, value string) {
+	if field, found := c.fields()[key]; found {
+		*field = value
+		return
+	}
+	if c.extra == nil {
+		c.extra = make(map[string]string)
 	}
+	c.extra[key] = value
+}
+
+func downloadGradleBuildTemplate(ctx context.Context) {
+	log.Info("")
+	log.Info("> Downloading Gradle build template to directory [%s]", args.dir)
+
+	rememberExistingUniqueId()
 
-	url := "http://helga/scm/hg/gradle/solution-plugin/raw-file/tip/setup/template-build.gradle"
+	resolveTemplateRevision(ctx)
 
-	downloadFromUrl(url, args.dir, "build.gradle", args.username, args.password)
+	downloadFromUrl(ctx, templateUrl()+"template-build.gradle", args.dir, "build.gradle", args.username, args.password)
 }
 
 func setupDefaultGradleConfig() {
-	uuid4, err := uuid.NewV4()
-	if err != nil {
-		log.Error("Error while generating UUID:", err)
-	}
-
-	gradle = GradleConfig{
-		version:                 "1.0.0-SNAPSHOT",
-		group:                   "com.topdesk.solution.customer",
-		description:             "Tool for customizing icons in the Self Service Desk",
-		customerName:            "Customer Name",
-		projectFullName:         "Project Name",
-		internalProjectName:     "customer-name_project-name",
-		tasVersion:              "5.5.1",
-		isXfgProject:            "false",
-		testCase:                "",
-		customerReferenceNumber: "",
-		uniqueId:                uuid4.String(),
-		projectType:             "forms,lookandfeel,labels,reports,modifiedcards,xmlimport,addon,other",
-	}
+	gradle = GradleConfig{}
+	applyQuestionDefaults("project")
+	applyQuestionDefaults("solution")
+	applyQuestionDefaults("plugin")
 }
 
-func collectGradleConfig() {
+func collectGradleConfig(ctx context.Context) {
 	log.Info("> Processing new settings for build.gradle:")
 
 	log.Notice("You can later edit this normally in your editor of choice.")
 	log.Notice("The values inside the brackets [] will be used if you enter nothing.")
+	log.Notice("Enter < to go back to the previous question or @name to jump to a question, e.g. @tasVersion.")
 
-	requestInput(&gradle.version, `
-VERSION:
-Version of the project, e.g: 1.0.0
-Add -SNAPSHOT to indicate it is a work in progress
-    `)
-
-	requestInput(&gradle.group, `
-GROUP:
-One of these depending on the type of your project:
- - com.topdesk.solution.customer (for a TOPdesk client)
- - com.topdesk.solution.addon
- - com.topdesk.solution.prototype
- - com.topdesk.solution.tool (intended for internal use, not limited to consultancy)
- - com.topdesk.solution.lib (a jar not a bespoke zip)
- - com.topdesk.solution.event (like a look & feel for a world cup etc)
- - com.topdesk.solution.product
-    `)
-
-	requestInput(&gradle.description, `
-DESCRIPTION:
-Short description of the project
-    `)
-
-	requestInput(&gradle.customerName, `
-CUSTOMERNAME:
-Full name of the customer: will end up as part of the ZIP file's name.
-    `)
-
-	requestInput(&gradle.projectFullName, `
-PROJECTFULLNAME:
-Full name of the project: will end up as part of the ZIP file's name.
-    `)
-
-	requestInput(&gradle.internalProjectName, `
-INTERNALPROJECTNAME:
-Used as artifact id for publishing to nexus. Use the format 'customer-name_project-name' if it's a
-customer project, otherwise use 'project-name', or 'project-name-x.x' if you release TOPdesk specific        builds (e.g: for an add-on).
-    `)
-
-	requestInput(&gradle.tasVersion, `
-TASVERSION:
-The TAS version you want to work on, e.g. 5.4.1
-    `)
-
-	requestInput(&gradle.isXfgProject, `
-ISXFGPROJECT:
-Set this to true if this project uses XFG forms. The zip will be locked automatically.
-This also applies to TOPdesk 5.2+.
-    `)
-
-	requestInput(&gradle.testCase, `
-TESTCASE:
-The test case id associated with this solution (used by TOPdesk's test team).
-    `)
-
-	requestInput(&gradle.customerReferenceNumber, `
-CUSTOMERREFERENCENUMBER:
-The customer reference number of the customer this project is created for.
-You can find this on the customer card in TOPhelp.
-    `)
-
-	requestInput(&gradle.uniqueId, `
-UNIQUEID:
-A unique identifier for your Solution. It can be anything, but it is mandatory when creating a zip.
-SaaS will use this to match old and new versions, and it can also be used by the Portfolio.
-It is automatically generated but you can choose to overwrite it.
-    `)
-
-	requestInput(&gradle.projectType, `
-PROJECTTYPE:
-It is not mandatory, but it there will be a warning if it isn’t filled in.
-This makes sure we can categorize Solutions better in the future.
-
-Please provide a comma-separated list of a subset of the following:
-forms,lookandfeel,labels,reports,modifiedcards,xmlimport,addon,other
-    `)
-
+	wizard := newWizard("project", "solution", "plugin")
+	wizard.run(ctx)
+	wizard.review(ctx)
 }
 
 func createNewConfigPart() []string {
@@ -150,22 +103,20 @@ func createNewConfigPart() []string {
 	newPart = append(newPart, "/******************************************")
 	newPart = append(newPart, " Generated by Solutionist "+version)
 	newPart = append(newPart, " ******************************************/")
-	newPart = append(newPart, "version     '"+gradle.version+"'")
-	newPart = append(newPart, "group       '"+gradle.group+"'")
-	newPart = append(newPart, "description '"+gradle.description+"'")
+	for _, question := range wizardQuestions {
+		if question.Section == "project" {
+			newPart = append(newPart, fmt.Sprintf("%-12s%s", question.Id, gradleLiteral(question, gradle.get(question.Id))))
+		}
+	}
 	newPart = append(newPart, "")
 	newPart = append(newPart, "apply plugin: 'solution'")
 	newPart = append(newPart, "")
 	newPart = append(newPart, "solution {")
-	newPart = append(newPart, "    internalProjectName '"+gradle.internalProjectName+"'")
-	newPart = append(newPart, "    customerName '"+gradle.customerName+"'")
-	newPart = append(newPart, "    projectFullName '"+gradle.projectFullName+"'")
-	newPart = append(newPart, "    tasVersion '"+gradle.tasVersion+"'")
-	newPart = append(newPart, "    isXfgProject "+gradle.isXfgProject)
-	newPart = append(newPart, "    testCase '"+gradle.testCase+"'")
-	newPart = append(newPart, "    customerReferenceNumber '"+gradle.customerReferenceNumber+"'")
-	newPart = append(newPart, "    uniqueId '"+gradle.uniqueId+"'")
-	newPart = append(newPart, "    projectType '"+gradle.projectType+"'")
+	for _, question := range wizardQuestions {
+		if question.Section == "solution" {
+			newPart = append(newPart, "    "+question.Id+" "+gradleLiteral(question, gradle.get(question.Id)))
+		}
+	}
 	newPart = append(newPart, "}")
 	newPart = append(newPart, "")
 	newPart = append(newPart, "/******************************************")
@@ -209,6 +160,9 @@ func patchGradleConfig() {
 	newLines = append(newLines, lastPart...)
 
 	output := strings.Join(newLines, "\n")
+	if pluginVersion := gradle.get("solutionPluginVersion"); pluginVersion != "" && templateSolutionPluginVersion != "" {
+		output = solutionPluginPattern.ReplaceAllLiteralString(output, "solution-plugin:"+pluginVersion)
+	}
 	if err = ioutil.WriteFile(args.dir+"/build.gradle", []byte(output), 0777); err != nil {
 		log.Critical("Could not write to build.gradle: %s", err)
 	}
@@ -1,11 +1,24 @@
 package main
 
 import (
-	"github.com/franela/goreq"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
+	"net/url"
 	"strings"
 )
 
+func helgaHgUrl() string {
+	return profile.HelgaUrl + "hg/"
+}
+
+func helgaApiUrl() string {
+	return profile.HelgaUrl + "api/rest/"
+}
+
 // tags are used by reflection
 type HelgaConfig struct {
 	Name        string `json:"name"`
@@ -15,15 +28,32 @@ type HelgaConfig struct {
 	Public      string `json:"public"`
 }
 
+func (c *HelgaConfig) fields() map[string]*string {
+	return map[string]*string{
+		"name":        &c.Name,
+		"type":        &c.Type,
+		"contact":     &c.Contact,
+		"description": &c.Description,
+		"public":      &c.Public,
+	}
+}
+
+func (c *HelgaConfig) get(key string) string {
+	if field, found := c.fields()[key]; found {
+		return *field
+	}
+	return ""
+}
+
+func (c *HelgaConfig) set(key string, value string) {
+	if field, found := c.fields()[key]; found {
+		*field = value
+	} else {
+		log.Warning("Helga repositories have no setting %s", key)
+	}
+}
+
 func setupDefaultHelgaConfig() {
-	/* names
-	Customer project:                                customers/[reference-number]_[customer-name]/[project-name]
-	Add-on:                                          add-ons/[add-on-name]
-	Prototype:                                       prototypes/[prototype-name]
-	Tool (used by consultants, i.e.: XFG, XIM):      tools/[tool-project-name]
-	Libraries:                                       resources/[internal-project-name]
-	Playground/Apekooien:                            sandbox/[username]/[project-name]
-	*/
 	helga = HelgaConfig{
 		Name:        "",
 		Type:        "hg",
@@ -31,70 +61,118 @@ func setupDefaultHelgaConfig() {
 		Contact:     args.username + "@topdesk.com",
 		Public:      "true",
 	}
+	applyQuestionDefaults("helga")
 }
 
-func collectHelgaConfig() {
+func collectHelgaConfig(ctx context.Context) {
 	log.Info("> Processing settings for new repo on Helga:")
 	log.Notice("The values inside the brackets [] will be used if you enter nothing.")
 
-	requestInput(&helga.Name, `
-NAME:
-One of these depending on the type of your project:
-- customers/[reference-number]_[customer-name]/[project-name]
-- add-ons/[add-on-name]
-- prototypes/[prototype-name]
-- tools/[tool-project-name] (Tool, also used by nondevs, e.g. XFG, XIM)
-- resources/[internal-project-name] (Libraries go here)
-- events/[internal-project-name]
-- products/[internal-project-name]
-- sandbox/[username]/[project-name] (Playground/Apekooien)
-
-Suggestions are based on the chosen project group.
-    `)
-	/*
-		GROUP:
-		One of these depending on the type of your project:
-		- com.topdesk.solution.customer (for a TOPdesk client)
-		- com.topdesk.solution.addon
-		- com.topdesk.solution.prototype
-		- com.topdesk.solution.tool (intended for internal use, not limited to consultancy)
-		- com.topdesk.solution.lib (a jar not a bespoke zip)
-		- com.topdesk.solution.event (like a look & feel for a world cup etc)
-		- com.topdesk.solution.product
-	*/
+	newWizard("helga").run(ctx)
+}
+
+func helgaRepoUrl() string {
+	return helgaHgUrl() + helga.Name
 }
 
-func createHelgaRepo() {
-	res, err := goreq.Request{
-		Method:            "POST",
-		Uri:               "http://helga/scm/api/rest/repositories",
-		BasicAuthUsername: args.username,
-		BasicAuthPassword: args.password,
-		ContentType:       "application/json",
-		Body:              helga,
-	}.Do()
+// how often the user may retype a wrong password
+const maxLoginAttempts = 3
+
+var errWrongCredentials = errors.New("wrong username or password")
+
+// authenticateHelga logs in to SCM-Manager and fails on wrong credentials.
+func authenticateHelga(ctx context.Context, username string, password string) error {
+	form := url.Values{}
+	form.Set("username", username)
+	form.Set("password", password)
+
+	res, err := httpRequest(ctx, "POST", helgaApiUrl()+"authentication/login", "", "", "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
 	if err != nil {
-		log.Fatalf("Could not create repo on Helga: %s", err)
-	} else {
-		s, _ := res.Body.ToString()
-		if s == "" {
-			log.Notice("Repository created at: http://helga/scm/hg/%s", helga.Name)
-			linkHelgaRepo()
-		} else {
-			log.Critical("Something went wrong:\n  %v", s)
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 401 {
+		return errWrongCredentials
+	}
+	if res.StatusCode != 200 {
+		return fmt.Errorf("%s", res.Status)
+	}
+	return nil
+}
+
+// loginToHelga verifies the credentials before any question is asked and asks
+// for them again if they are wrong.
+func loginToHelga(ctx context.Context) {
+	log.Info("")
+	log.Info("> Logging in to Helga")
+
+	requestCredentials()
+	for attempt := 1; ; attempt++ {
+		err := authenticateHelga(ctx, args.username, args.password)
+		if err == nil {
+			log.Notice("Logged in as %s", args.username)
+			return
+		}
+		if err != errWrongCredentials {
+			log.Fatalf("Could not log in to Helga: %s", err)
+		}
+		if attempt == maxLoginAttempts {
+			log.Fatalf("Could not log in to Helga after %d attempts: %s", attempt, err)
 		}
+
+		log.Error("Wrong username or password, please try again")
+		requestInput(&args.username, "Username needed:")
+		args.password = ""
+		requestHiddenInput(&args.password, "Password needed:")
 	}
 }
 
+// createHelgaRepo returns whether the repository was created.
+func createHelgaRepo(ctx context.Context) bool {
+	body, err := json.Marshal(helga)
+	if err != nil {
+		log.Fatalf("Could not create repo on Helga: %s", err)
+	}
+
+	res, err := httpRequest(ctx, "POST", helgaApiUrl()+"repositories", args.username, args.password, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Fatalf("Could not create repo on Helga: %s", err)
+	}
+	defer res.Body.Close()
+	response, _ := ioutil.ReadAll(res.Body)
+	s := string(response)
+	if s != "" {
+		log.Critical("Something went wrong:\n  %v", s)
+		return false
+	}
+	log.Notice("Repository created at: %s", helgaRepoUrl())
+	recordHelgaUrl(helgaRepoUrl())
+	linkHelgaRepo()
+	return true
+}
+
 func linkHelgaRepo() {
-	hgrc := make([]string, 0)
-	hgrc = append(hgrc, "[paths]")
-	hgrc = append(hgrc, "default = http://helga/scm/hg/"+helga.Name)
+	hgrcPath := args.dir + "/.hg/hgrc"
+	hgrc, err := readHgrc(hgrcPath)
+	if err != nil {
+		log.Critical("Could not read hgrc: %s", err)
+		return
+	}
+
+	hgrc.set("paths", "default", helgaRepoUrl())
+
+	if _, found := hgrc.get("ui", "username"); !found && helga.Contact != "" {
+		hgrc.set("ui", "username", args.username+" <"+helga.Contact+">")
+	}
+
+	// passwords are never stored, hg will ask for them
+	hgrc.set("auth", "helga.prefix", strings.TrimSuffix(helgaHgUrl(), "/"))
+	hgrc.set("auth", "helga.username", args.username)
 
-	output := strings.Join(hgrc, "\n")
-	if err := ioutil.WriteFile(args.dir+"/.hg/hgrc", []byte(output), 0777); err != nil {
+	if err := hgrc.write(hgrcPath); err != nil {
 		log.Critical("Could not write to hgrc: %s", err)
 	} else {
-		log.Notice(".hg/hgrc created accordingly")
+		log.Notice(".hg/hgrc updated accordingly")
 	}
 }
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// HgrcSection keeps the raw lines of a section so that comments and
+// formatting survive a round trip. The header line is not part of lines.
+type HgrcSection struct {
+	name  string
+	lines []string
+}
+
+// Hgrc is a minimal editor for Mercurial's INI-style config files.
+// Lines before the first section header are kept in the unnamed section.
+type Hgrc struct {
+	sections []*HgrcSection
+}
+
+func parseHgrc(content string) *Hgrc {
+	h := &Hgrc{sections: []*HgrcSection{{name: ""}}}
+	current := h.sections[0]
+
+	content = strings.Replace(content, "\r\n", "\n", -1)
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") && !isHgrcContinuation(line) {
+			current = &HgrcSection{name: strings.TrimSpace(trimmed[1 : len(trimmed)-1])}
+			h.sections = append(h.sections, current)
+			continue
+		}
+		current.lines = append(current.lines, line)
+	}
+	return h
+}
+
+func readHgrc(path string) (*Hgrc, error) {
+	input, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return parseHgrc(""), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseHgrc(string(input)), nil
+}
+
+func (h *Hgrc) String() string {
+	lines := make([]string, 0)
+	for _, section := range h.sections {
+		if section.name != "" {
+			lines = append(lines, "["+section.name+"]")
+		}
+		lines = append(lines, section.lines...)
+	}
+	return strings.TrimLeft(strings.Join(lines, "\n"), "\n")
+}
+
+func (h *Hgrc) write(path string) error {
+	output := h.String()
+	if !strings.HasSuffix(output, "\n") {
+		output += "\n"
+	}
+	return ioutil.WriteFile(path, []byte(output), 0644)
+}
+
+func (h *Hgrc) section(name string) *HgrcSection {
+	for _, section := range h.sections {
+		if section.name == name {
+			return section
+		}
+	}
+	return nil
+}
+
+// get returns the value of key in section and whether it was found.
+// Continuation lines are joined with a single blank.
+func (h *Hgrc) get(sectionName string, key string) (string, bool) {
+	section := h.section(sectionName)
+	if section == nil {
+		return "", false
+	}
+	start, end := section.find(key)
+	if start < 0 {
+		return "", false
+	}
+	_, value := splitHgrcLine(section.lines[start])
+	for _, line := range section.lines[start+1 : end] {
+		value += " " + strings.TrimSpace(line)
+	}
+	return strings.TrimSpace(value), true
+}
+
+// set adds or replaces key in section. A missing section is appended to the end.
+func (h *Hgrc) set(sectionName string, key string, value string) {
+	section := h.section(sectionName)
+	if section == nil {
+		last := h.sections[len(h.sections)-1]
+		if len(last.lines) > 0 && strings.TrimSpace(last.lines[len(last.lines)-1]) != "" {
+			last.lines = append(last.lines, "")
+		}
+		section = &HgrcSection{name: sectionName}
+		h.sections = append(h.sections, section)
+	}
+
+	entry := key + " = " + value
+	start, end := section.find(key)
+	if start >= 0 {
+		lines := append([]string{}, section.lines[:start]...)
+		lines = append(lines, entry)
+		section.lines = append(lines, section.lines[end:]...)
+		return
+	}
+
+	insertAt := len(section.lines)
+	for insertAt > 0 && strings.TrimSpace(section.lines[insertAt-1]) == "" {
+		insertAt--
+	}
+	lines := append([]string{}, section.lines[:insertAt]...)
+	lines = append(lines, entry)
+	section.lines = append(lines, section.lines[insertAt:]...)
+}
+
+// find returns the line range [start, end) holding key including its
+// continuation lines, or -1 if the key is not present.
+func (s *HgrcSection) find(key string) (int, int) {
+	for i, line := range s.lines {
+		if isHgrcContinuation(line) || isHgrcComment(line) {
+			continue
+		}
+		name, _ := splitHgrcLine(line)
+		if name != key {
+			continue
+		}
+		end := i + 1
+		for end < len(s.lines) && isHgrcContinuation(s.lines[end]) {
+			end++
+		}
+		return i, end
+	}
+	return -1, -1
+}
+
+func splitHgrcLine(line string) (string, string) {
+	parts := strings.SplitN(line, "=", 2)
+	if len(parts) != 2 {
+		return strings.TrimSpace(line), ""
+	}
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+}
+
+func isHgrcComment(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";")
+}
+
+func isHgrcContinuation(line string) bool {
+	return strings.TrimSpace(line) != "" && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t"))
+}
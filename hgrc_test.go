@@ -0,0 +1,45 @@
+package main
+
+import (
+	. "github.com/franela/goblin"
+	. "github.com/onsi/gomega"
+	"testing"
+)
+
+func TestHgrc(t *testing.T) {
+	g := Goblin(t)
+
+	RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	g.Describe("Editing an existing hgrc", func() {
+		g.It("Should keep unrelated sections and comments", func() {
+			hgrc := parseHgrc("# created by hg init\n[extensions]\nrebase =\n\n[paths]\ndefault = http://old\n")
+			hgrc.set("paths", "default", "http://helga/scm/hg/sandbox/me/project")
+
+			Expect(hgrc.String()).Should(Equal("# created by hg init\n[extensions]\nrebase =\n\n[paths]\ndefault = http://helga/scm/hg/sandbox/me/project\n"))
+		})
+
+		g.It("Should add missing sections at the end", func() {
+			hgrc := parseHgrc("[ui]\nusername = me\n")
+			hgrc.set("auth", "helga.prefix", "http://helga/scm/hg")
+
+			Expect(hgrc.String()).Should(Equal("[ui]\nusername = me\n\n[auth]\nhelga.prefix = http://helga/scm/hg"))
+		})
+
+		g.It("Should replace continuation lines of a value", func() {
+			hgrc := parseHgrc("[paths]\ndefault = http://a\n  http://b\nother = x\n")
+			value, found := hgrc.get("paths", "default")
+			Expect(found).Should(BeTrue())
+			Expect(value).Should(Equal("http://a http://b"))
+
+			hgrc.set("paths", "default", "http://c")
+			Expect(hgrc.String()).Should(Equal("[paths]\ndefault = http://c\nother = x\n"))
+		})
+
+		g.It("Should not find keys of other sections", func() {
+			hgrc := parseHgrc("[ui]\nusername = me\n")
+			_, found := hgrc.get("auth", "username")
+			Expect(found).Should(BeFalse())
+		})
+	})
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"sync"
+	"time"
+)
+
+const (
+	defaultHttpRetries = 3
+	// doubled after every failed attempt
+	initialRetryBackoff = 500 * time.Millisecond
+)
+
+var (
+	httpClient     *http.Client
+	httpClientOnce sync.Once
+)
+
+// newHttpClient creates a client honouring HTTP_PROXY, HTTPS_PROXY and
+// NO_PROXY which also trusts the certificates in caBundle, if given.
+// It keeps cookies, so a session created by a login is reused.
+func newHttpClient(caBundle string, timeout time.Duration) (*http.Client, error) {
+	transport := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+
+	if caBundle != "" {
+		pem, err := ioutil.ReadFile(caBundle)
+		if err != nil {
+			return nil, fmt.Errorf("could not read CA bundle: %s", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", caBundle)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	jar, _ := cookiejar.New(nil)
+	return &http.Client{Transport: transport, Jar: jar, Timeout: timeout}, nil
+}
+
+// sharedHttpClient is used for all requests to Helga and Nexus.
+func sharedHttpClient() *http.Client {
+	httpClientOnce.Do(func() {
+		client, err := newHttpClient(profile.CaBundle, args.httpTimeout)
+		if err != nil {
+			log.Fatalf("Could not set up HTTP client: %s", err)
+		}
+		httpClient = client
+	})
+	return httpClient
+}
+
+// httpRequest sends a request using basic authentication if a username is
+// given. It is cancelled with ctx and times out after -http-timeout, which
+// includes reading the body. Idempotent requests are retried.
+func httpRequest(ctx context.Context, method string, url string, username string, password string, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if username != "" {
+		req.SetBasicAuth(username, password)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	return doWithRetry(sharedHttpClient(), req, profile.httpRetries(), initialRetryBackoff)
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+	return false
+}
+
+// doWithRetry retries idempotent requests on connection errors and 5xx
+// responses, waiting backoff before the first retry and doubling it afterwards.
+func doWithRetry(client *http.Client, req *http.Request, retries int, backoff time.Duration) (*http.Response, error) {
+	if !isIdempotent(req.Method) || (req.Body != nil && req.GetBody == nil) {
+		retries = 0
+	}
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		res, err := client.Do(req)
+		retryable := err != nil || res.StatusCode >= 500
+		if !retryable || attempt >= retries {
+			return res, err
+		}
+
+		if err != nil {
+			log.Debug("%s %s failed, retrying in %s: %s", req.Method, req.URL, backoff, err)
+		} else {
+			log.Debug("%s %s returned %s, retrying in %s", req.Method, req.URL, res.Status, backoff)
+			res.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/pem"
+	"fmt"
+	. "github.com/franela/goblin"
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHttpClient(t *testing.T) {
+	g := Goblin(t)
+
+	RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	g.Describe("Retrying requests", func() {
+		var ts *httptest.Server
+		requests := 0
+
+		g.Before(func() {
+			ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if r.URL.Path == "/flaky" && requests < 3 {
+					w.WriteHeader(503)
+					return
+				}
+				if r.URL.Path == "/broken" {
+					w.WriteHeader(500)
+					return
+				}
+				fmt.Fprint(w, "ok")
+			}))
+		})
+		g.It("Should retry GET requests on 5xx", func() {
+			requests = 0
+			client, _ := newHttpClient("", 0)
+			req, _ := http.NewRequest("GET", ts.URL+"/flaky", nil)
+			res, err := doWithRetry(client, req, 3, time.Millisecond)
+			Expect(err).Should(BeNil())
+			Expect(res.StatusCode).Should(Equal(200))
+			Expect(requests).Should(Equal(3))
+		})
+		g.It("Should give up after the configured retries", func() {
+			requests = 0
+			client, _ := newHttpClient("", 0)
+			req, _ := http.NewRequest("GET", ts.URL+"/broken", nil)
+			res, err := doWithRetry(client, req, 2, time.Millisecond)
+			Expect(err).Should(BeNil())
+			Expect(res.StatusCode).Should(Equal(500))
+			Expect(requests).Should(Equal(3))
+		})
+		g.It("Should not retry POST requests", func() {
+			requests = 0
+			client, _ := newHttpClient("", 0)
+			req, _ := http.NewRequest("POST", ts.URL+"/broken", strings.NewReader("{}"))
+			res, err := doWithRetry(client, req, 3, time.Millisecond)
+			Expect(err).Should(BeNil())
+			Expect(res.StatusCode).Should(Equal(500))
+			Expect(requests).Should(Equal(1))
+		})
+		g.After(func() {
+			ts.Close()
+		})
+	})
+
+	g.Describe("Trusting a custom CA bundle", func() {
+		var ts *httptest.Server
+		caBundle := "test_ca.pem"
+
+		g.Before(func() {
+			ts = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, "ok")
+			}))
+			certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+			ioutil.WriteFile(caBundle, certificate, 0644)
+		})
+		g.It("Should reject the server without the bundle", func() {
+			client, _ := newHttpClient("", 0)
+			_, err := client.Get(ts.URL)
+			Expect(err).ShouldNot(BeNil())
+		})
+		g.It("Should accept the server with the bundle", func() {
+			client, err := newHttpClient(caBundle, 0)
+			Expect(err).Should(BeNil())
+			res, err := client.Get(ts.URL)
+			Expect(err).Should(BeNil())
+			Expect(res.StatusCode).Should(Equal(200))
+		})
+		g.It("Should fail on a bundle without certificates", func() {
+			_, err := newHttpClient("http_test.go", 0)
+			Expect(err).ShouldNot(BeNil())
+		})
+		g.After(func() {
+			os.Remove(caBundle)
+			ts.Close()
+		})
+	})
+}
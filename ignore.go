@@ -0,0 +1,92 @@
+package main
+
+import (
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// defaultIgnorePatterns use the .gitignore syntax; a leading slash only
+// matches in the project's root, so e.g. src/main/java/bin is kept.
+var defaultIgnorePatterns = []string{
+	".gradle",
+	"/build/",
+	"/out/",
+	"solutionist.log",
+	"solutionist-result.json",
+	".idea",
+	"*.iml",
+	"*.ipr",
+	"*.iws",
+	".classpath",
+	".project",
+	".settings",
+	"/bin/",
+	".DS_Store",
+	"Thumbs.db",
+	"*~",
+}
+
+var projectTypeIgnorePatterns = map[string][]string{
+	"lookandfeel": {"*.psd", ".sass-cache"},
+	"reports":     {"*.bak"},
+	"xmlimport":   {"*.log"},
+}
+
+func createIgnorePatterns() []string {
+	patterns := make([]string, 0)
+	patterns = append(patterns, defaultIgnorePatterns...)
+
+	projectTypes := strings.Split(gradle.projectType, ",")
+	sort.Strings(projectTypes)
+	for _, projectType := range projectTypes {
+		patterns = append(patterns, projectTypeIgnorePatterns[strings.TrimSpace(projectType)]...)
+	}
+	patterns = append(patterns, profile.IgnorePatterns...)
+
+	unique := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		if pattern == "" || seen[pattern] {
+			continue
+		}
+		seen[pattern] = true
+		unique = append(unique, pattern)
+	}
+	return unique
+}
+
+func writeIgnoreFiles() {
+	log.Info("")
+	log.Info("> Writing ignore files")
+
+	patterns := createIgnorePatterns()
+	writeIgnoreFile(".hgignore", append([]string{"syntax: glob"}, hgIgnorePatterns(patterns)...))
+	writeIgnoreFile(".gitignore", patterns)
+}
+
+// hgIgnorePatterns turns patterns rooted with a leading slash into regular
+// expressions, as globs in .hgignore match in every directory.
+func hgIgnorePatterns(patterns []string) []string {
+	lines := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, "/") {
+			expression := regexp.QuoteMeta(strings.Trim(pattern, "/"))
+			expression = strings.Replace(expression, `\*`, "[^/]*", -1)
+			expression = strings.Replace(expression, `\?`, "[^/]", -1)
+			pattern = "relre:^" + expression + "(/|$)"
+		}
+		lines = append(lines, pattern)
+	}
+	return lines
+}
+
+func writeIgnoreFile(fileName string, lines []string) {
+	output := "# Generated by Solutionist " + version + "\n" + strings.Join(lines, "\n") + "\n"
+	if err := ioutil.WriteFile(args.dir+"/"+fileName, []byte(output), 0644); err != nil {
+		log.Critical("Could not write to %s: %s", fileName, err)
+	} else {
+		log.Notice("%s created", fileName)
+	}
+}
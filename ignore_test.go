@@ -0,0 +1,52 @@
+package main
+
+import (
+	. "github.com/franela/goblin"
+	. "github.com/onsi/gomega"
+	"testing"
+)
+
+func TestIgnore(t *testing.T) {
+	g := Goblin(t)
+
+	RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	g.Describe("Creating ignore patterns", func() {
+		var savedGradle GradleConfig
+		var savedProfile Profile
+		g.Before(func() {
+			savedGradle = gradle
+			savedProfile = profile
+		})
+		g.After(func() {
+			gradle = savedGradle
+			profile = savedProfile
+		})
+
+		g.It("Should add the patterns of every project type and the profile once", func() {
+			gradle = GradleConfig{projectType: "reports,lookandfeel"}
+			profile = Profile{IgnorePatterns: []string{"*.tmp", "*.bak", "", ".gradle"}}
+
+			patterns := createIgnorePatterns()
+			Expect(patterns[:len(defaultIgnorePatterns)]).Should(Equal(defaultIgnorePatterns))
+			Expect(patterns[len(defaultIgnorePatterns):]).Should(Equal([]string{"*.psd", ".sass-cache", "*.bak", "*.tmp"}))
+		})
+		g.It("Should only use the defaults without project type", func() {
+			gradle = GradleConfig{}
+			profile = Profile{}
+
+			Expect(createIgnorePatterns()).Should(Equal(defaultIgnorePatterns))
+		})
+	})
+
+	g.Describe("Writing .hgignore", func() {
+		g.It("Should only match rooted patterns in the root", func() {
+			Expect(hgIgnorePatterns([]string{"/bin/", "/solutionist.log", "/*.tmp", "*.iml"})).Should(Equal([]string{
+				"relre:^bin(/|$)",
+				`relre:^solutionist\.log(/|$)`,
+				"relre:^[^/]*\\.tmp(/|$)",
+				"*.iml",
+			}))
+		})
+	})
+}
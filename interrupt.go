@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// how long running commands get to terminate after an interrupt
+const interruptGracePeriod = 10 * time.Second
+
+var (
+	cleanupMutex  sync.Mutex
+	cleanups      = make(map[int]func())
+	nextCleanupId int
+	runningCmds   sync.WaitGroup
+	abortOnce     sync.Once
+)
+
+// addCleanup registers a function which runs if Solutionist is interrupted.
+// The returned function unregisters it again.
+func addCleanup(cleanup func()) func() {
+	cleanupMutex.Lock()
+	defer cleanupMutex.Unlock()
+
+	id := nextCleanupId
+	nextCleanupId++
+	cleanups[id] = cleanup
+	return func() {
+		cleanupMutex.Lock()
+		defer cleanupMutex.Unlock()
+		delete(cleanups, id)
+	}
+}
+
+// handleInterrupts cancels the context on SIGINT/SIGTERM, which interrupts the
+// current child process, and aborts after it has stopped.
+func handleInterrupts(cancel context.CancelFunc) {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	<-signals
+	log.Warning("")
+	log.Warning("Interrupted, stopping. Interrupt again to stop immediately.")
+	cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		runningCmds.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-signals:
+	case <-time.After(interruptGracePeriod):
+	}
+	abort()
+}
+
+// stopOnCancel makes a command started with exec.CommandContext stop like
+// after Ctrl+C when its context is cancelled, so it can release locks and
+// clean up. It is killed if it has not stopped within the grace period, or
+// right away where it cannot be interrupted, like on Windows.
+func stopOnCancel(cmd *exec.Cmd) {
+	cmd.Cancel = func() error {
+		if err := cmd.Process.Signal(os.Interrupt); err != nil {
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+	cmd.WaitDelay = interruptGracePeriod
+}
+
+// abort runs all registered cleanups, newest first, and ends the program.
+func abort() {
+	abortOnce.Do(func() {
+		cleanupMutex.Lock()
+		ids := make([]int, 0, len(cleanups))
+		for id := range cleanups {
+			ids = append(ids, id)
+		}
+		cleanupMutex.Unlock()
+
+		sort.Ints(ids)
+		for i := len(ids) - 1; i >= 0; i-- {
+			cleanupMutex.Lock()
+			cleanup := cleanups[ids[i]]
+			cleanupMutex.Unlock()
+			if cleanup != nil {
+				cleanup()
+			}
+		}
+
+		log.Critical("This ended abruptly.")
+		os.Exit(130)
+	})
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	. "github.com/franela/goblin"
+	. "github.com/onsi/gomega"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestInterrupt(t *testing.T) {
+	g := Goblin(t)
+
+	RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	g.Describe("Cancelling a command", func() {
+		g.It("Should interrupt it instead of killing it", func() {
+			if runtime.GOOS == "windows" {
+				return
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			cmd := exec.CommandContext(ctx, "sh", "-c", "trap 'echo stopped; exit 3' INT; while true; do sleep 0.1; done")
+			stopOnCancel(cmd)
+			time.AfterFunc(200*time.Millisecond, cancel)
+
+			output, _ := cmd.Output()
+			Expect(string(output)).Should(Equal("stopped\n"))
+			Expect(cmd.ProcessState.ExitCode()).Should(Equal(3))
+		})
+	})
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// JavaInstallation is the result of probing a JDK referenced by an env var.
+type JavaInstallation struct {
+	key     string
+	home    string
+	version string
+	major   int
+	err     error
+}
+
+// defaultTasJavaVersions maps the lowest TAS version of a range to the JDK it
+// needs, unless the profile has tasJavaVersions. They are the JDKs of the
+// JAVA_HOME_6, JAVA_HOME_7 and JAVA_HOME_8 variables the environment check
+// always asked for: Java 7 since TAS 5.0 and Java 8 since TAS 5.5.
+var defaultTasJavaVersions = map[string]int{
+	"0":   6,
+	"5.0": 7,
+	"5.5": 8,
+}
+
+var javaVersionPattern = regexp.MustCompile(`version "([^"]+)"`)
+
+func javaExecutable(javaHome string) string {
+	name := "java"
+	if runtime.GOOS == "windows" {
+		name = "java.exe"
+	}
+	return filepath.Join(javaHome, "bin", name)
+}
+
+// parseJavaVersion reads the output of 'java -version' and returns the full
+// version and the major version, i.e. 8 for both "1.8.0_101" and "8.0.1".
+func parseJavaVersion(output string) (string, int, error) {
+	match := javaVersionPattern.FindStringSubmatch(output)
+	if match == nil {
+		return "", 0, fmt.Errorf("no version found in %q", strings.TrimSpace(output))
+	}
+	fullVersion := match[1]
+	parts := strings.FieldsFunc(fullVersion, func(r rune) bool { return r == '.' || r == '_' || r == '-' || r == '+' })
+	if len(parts) > 1 && parts[0] == "1" {
+		parts = parts[1:]
+	}
+	if len(parts) == 0 {
+		return fullVersion, 0, fmt.Errorf("unexpected version %q", fullVersion)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return fullVersion, 0, fmt.Errorf("unexpected version %q", fullVersion)
+	}
+	return fullVersion, major, nil
+}
+
+func detectJava(key string) JavaInstallation {
+	return probeJava(key, os.Getenv(key))
+}
+
+func probeJava(key string, home string) JavaInstallation {
+	java := JavaInstallation{key: key, home: home}
+	if java.home == "" {
+		java.err = fmt.Errorf("not set")
+		return java
+	}
+
+	executable := javaExecutable(java.home)
+	if _, err := os.Stat(executable); err != nil {
+		java.err = fmt.Errorf("%s not found", executable)
+		return java
+	}
+
+	output, err := exec.Command(executable, "-version").CombinedOutput()
+	if err != nil {
+		java.err = fmt.Errorf("%s -version failed: %s", executable, err)
+		return java
+	}
+
+	java.version, java.major, java.err = parseJavaVersion(string(output))
+	return java
+}
+
+// expectedJavaMajor returns the major version encoded in keys like JAVA_HOME_8
+// or 0 if the key does not name a version.
+func expectedJavaMajor(key string) int {
+	if !strings.HasPrefix(key, "JAVA_HOME_") {
+		return 0
+	}
+	major, err := strconv.Atoi(strings.TrimPrefix(key, "JAVA_HOME_"))
+	if err != nil {
+		return 0
+	}
+	return major
+}
+
+func checkJava(key string) JavaInstallation {
+	java := detectJava(key)
+	switch {
+	case java.err != nil:
+		log.Warning("%16s"+": %s (%s)", key, java.home, java.err)
+	case expectedJavaMajor(key) != 0 && expectedJavaMajor(key) != java.major:
+		log.Warning("%16s"+": %s (Java %s, expected Java %d)", key, java.home, java.version, expectedJavaMajor(key))
+	default:
+		log.Notice("%16s"+": %s (Java %s)", key, java.home, java.version)
+	}
+	return java
+}
+
+// requiredJavaMajor returns the JDK of the range with the highest lowest TAS
+// version not above tasVersion, or 0 if there is none.
+func requiredJavaMajor(tasVersion string) int {
+	required := 0
+	rangeStart := ""
+	for lowest, major := range profile.tasJavaVersions() {
+		if compareVersions(tasVersion, lowest) < 0 {
+			continue
+		}
+		if required == 0 || compareVersions(lowest, rangeStart) > 0 {
+			required = major
+			rangeStart = lowest
+		}
+	}
+	return required
+}
+
+// selectJavaForTasVersion points JAVA_HOME to the matching JAVA_HOME_x or an
+// installed JDK for the Gradle invocations if the current one does not fit the
+// chosen TAS version.
+func selectJavaForTasVersion() {
+	required := requiredJavaMajor(gradle.tasVersion)
+	log.Info("")
+	if required == 0 {
+		log.Warning("> No JDK known for TAS %s, add it to tasJavaVersions of your profile", gradle.tasVersion)
+		return
+	}
+	log.Info("> TAS %s requires Java %d", gradle.tasVersion, required)
+
+	current := detectJava("JAVA_HOME")
+	if current.err == nil && current.major == required {
+		log.Notice("JAVA_HOME already points to Java %s", current.version)
+		return
+	}
+
+	candidate := detectJava(fmt.Sprintf("JAVA_HOME_%d", required))
+	if candidate.err == nil && candidate.major == required {
+		os.Setenv("JAVA_HOME", candidate.home)
+		log.Notice("Using %s=%s as JAVA_HOME for Gradle", candidate.key, candidate.home)
+		return
+	}
+
+	if jdk, found := findJdk(discoverJdks(), required); found {
+		os.Setenv("JAVA_HOME", jdk.home)
+		log.Notice("Using Java %s from %s as JAVA_HOME for Gradle", jdk.version, jdk.home)
+		pinGradleJava(jdk)
+		return
+	}
+
+	if current.err != nil {
+		log.Warning("JAVA_HOME is not usable (%s) and %s is not usable either (%s)", current.err, candidate.key, candidate.err)
+	} else {
+		log.Warning("JAVA_HOME points to Java %s but TAS %s requires Java %d; set %s to fix this", current.version, gradle.tasVersion, required, candidate.key)
+	}
+}
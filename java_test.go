@@ -0,0 +1,58 @@
+package main
+
+import (
+	. "github.com/franela/goblin"
+	. "github.com/onsi/gomega"
+	"testing"
+)
+
+func TestJava(t *testing.T) {
+	g := Goblin(t)
+
+	RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	g.Describe("Parsing the output of java -version", func() {
+		g.It("Should understand the 1.x scheme", func() {
+			version, major, err := parseJavaVersion("java version \"1.8.0_101\"\nJava(TM) SE Runtime Environment (build 1.8.0_101-b13)")
+			Expect(err).Should(BeNil())
+			Expect(version).Should(Equal("1.8.0_101"))
+			Expect(major).Should(Equal(8))
+		})
+		g.It("Should understand the new scheme", func() {
+			_, major, err := parseJavaVersion("openjdk version \"11.0.2\" 2019-01-15")
+			Expect(err).Should(BeNil())
+			Expect(major).Should(Equal(11))
+		})
+		g.It("Should fail on unexpected output", func() {
+			_, _, err := parseJavaVersion("command not found")
+			Expect(err).ShouldNot(BeNil())
+		})
+		g.It("Should fail on versions without numbers", func() {
+			for _, output := range []string{`java version "."`, `java version "_"`, `java version "-+"`} {
+				_, _, err := parseJavaVersion(output)
+				Expect(err).ShouldNot(BeNil())
+			}
+		})
+	})
+
+	g.Describe("Mapping TAS versions to JDKs", func() {
+		g.It("Should pick the JDK of the matching range", func() {
+			Expect(requiredJavaMajor("4.6")).Should(Equal(6))
+			Expect(requiredJavaMajor("5.4.1")).Should(Equal(7))
+			Expect(requiredJavaMajor("5.5.1")).Should(Equal(8))
+			Expect(requiredJavaMajor("5.10")).Should(Equal(8))
+		})
+		g.It("Should prefer the ranges of the profile", func() {
+			savedProfile := profile
+			defer func() { profile = savedProfile }()
+			profile.TasJavaVersions = map[string]int{"5.5": 8, "6.0": 11}
+			Expect(requiredJavaMajor("5.9")).Should(Equal(8))
+			Expect(requiredJavaMajor("6.2")).Should(Equal(11))
+			Expect(requiredJavaMajor("5.0")).Should(Equal(0))
+		})
+		g.It("Should read the expected version from the variable name", func() {
+			Expect(expectedJavaMajor("JAVA_HOME_7")).Should(Equal(7))
+			Expect(expectedJavaMajor("JAVA_HOME")).Should(Equal(0))
+		})
+	})
+}
@@ -12,6 +12,10 @@ func (h Hidden) Redacted() interface{} {
 }
 
 func setupLogging() {
+	level := logging.INFO
+	if args.debug {
+		level = logging.DEBUG
+	}
 
 	consoleFormat := logging.MustStringFormatter("%{message}")
 
@@ -19,15 +23,21 @@ func setupLogging() {
 		consoleFormat = logging.MustStringFormatter("%{color}%{message}%{color:reset}")
 	}
 
-	consoleBackend := logging.NewLogBackend(os.Stdout, "", 0)
+	console := os.Stdout
+	if args.output == "json" {
+		// stdout is reserved for the summary
+		console = os.Stderr
+	}
+	consoleBackend := logging.NewLogBackend(console, "", 0)
 	consoleBackendFormatted := logging.NewBackendFormatter(consoleBackend, consoleFormat)
 	consoleBackendLeveled := logging.AddModuleLevel(consoleBackendFormatted)
-	consoleBackendLeveled.SetLevel(logging.INFO, "")
-	if args.debug {
-		logging.SetBackend(consoleBackendFormatted)
-	} else {
-		logging.SetBackend(consoleBackendLeveled)
+	consoleBackendLeveled.SetLevel(level, "")
+	if args.quiet {
+		// output of external commands only goes to the logfile and is
+		// shown on the console if the command fails
+		consoleBackendLeveled.SetLevel(logging.CRITICAL, cmdLogModule)
 	}
+	logging.SetBackend(consoleBackendLeveled, summaryBackend{})
 
 	if args.logfile {
 		file, err := os.Create(args.dir + "/solutionist.log")
@@ -35,16 +45,12 @@ func setupLogging() {
 			log.Error("%v", err)
 		} else {
 			fileFormat := logging.MustStringFormatter("%{time:15:04:05.000} %{shortfile:20s} %{level: 8s} | %{message}")
+			addCleanup(func() { file.Sync() })
 			fileBackend := logging.NewLogBackend(file, "", 0)
 			fileBackendFormatted := logging.NewBackendFormatter(fileBackend, fileFormat)
 			fileBackendLeveled := logging.AddModuleLevel(fileBackendFormatted)
-			fileBackendLeveled.SetLevel(logging.INFO, "")
-			if args.debug {
-				logging.SetBackend(consoleBackendFormatted, fileBackendFormatted)
-			} else {
-				logging.SetBackend(consoleBackendLeveled, fileBackendLeveled)
-			}
-
+			fileBackendLeveled.SetLevel(level, "")
+			logging.SetBackend(consoleBackendLeveled, fileBackendLeveled, summaryBackend{})
 		}
 	}
 }
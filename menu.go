@@ -0,0 +1,367 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// MenuOption is a valid value of a question and what it stands for.
+type MenuOption struct {
+	value       string
+	description string
+}
+
+// Menu is the state of a single- or multi-choice menu. The value of a
+// multi-choice menu is a comma-separated list in the order of the options.
+type Menu struct {
+	options  []MenuOption
+	multi    bool
+	cursor   int
+	selected []bool
+}
+
+func newMenu(options []MenuOption, multi bool, value string) *Menu {
+	menu := &Menu{options: options, multi: multi, selected: make([]bool, len(options))}
+	for _, part := range strings.Split(value, ",") {
+		for i, option := range options {
+			if option.value == strings.TrimSpace(part) {
+				menu.selected[i] = multi
+				if !multi {
+					menu.cursor = i
+				}
+			}
+		}
+	}
+	return menu
+}
+
+func (m *Menu) value() string {
+	if !m.multi {
+		return m.options[m.cursor].value
+	}
+	values := make([]string, 0)
+	for i, option := range m.options {
+		if m.selected[i] {
+			values = append(values, option.value)
+		}
+	}
+	return strings.Join(values, ",")
+}
+
+// handle processes a key: up, down, space, enter or a digit. Digits move the
+// cursor to an option and toggle it in a multi-choice menu. Returns true once
+// the choice is made.
+func (m *Menu) handle(key string) bool {
+	switch key {
+	case "up":
+		m.cursor = (m.cursor + len(m.options) - 1) % len(m.options)
+	case "down":
+		m.cursor = (m.cursor + 1) % len(m.options)
+	case "space":
+		if m.multi {
+			m.selected[m.cursor] = !m.selected[m.cursor]
+		}
+	case "enter":
+		return true
+	default:
+		if number, err := strconv.Atoi(key); err == nil && number >= 1 && number <= len(m.options) {
+			m.cursor = number - 1
+			if m.multi {
+				m.selected[m.cursor] = !m.selected[m.cursor]
+			}
+		}
+	}
+	return false
+}
+
+func (m *Menu) lines() []string {
+	lines := make([]string, 0, len(m.options))
+	for i, option := range m.options {
+		cursor := "  "
+		if i == m.cursor {
+			cursor = "> "
+		}
+		checkbox := ""
+		if m.multi {
+			checkbox = "[ ] "
+			if m.selected[i] {
+				checkbox = "[x] "
+			}
+		}
+		lines = append(lines, fmt.Sprintf("%s%s%d) %s", cursor, checkbox, i+1, menuOptionText(option)))
+	}
+	return lines
+}
+
+func menuOptionText(option MenuOption) string {
+	if option.description == "" {
+		return option.value
+	}
+	return option.value + " (" + option.description + ")"
+}
+
+// parseMenuAnswer accepts numbers or values, comma-separated for a
+// multi-choice menu.
+func parseMenuAnswer(answer string, options []MenuOption, multi bool) (string, error) {
+	parts := []string{answer}
+	if multi {
+		parts = strings.Split(answer, ",")
+	}
+
+	chosen := make(map[string]bool)
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		found := false
+		for i, option := range options {
+			if part == option.value || part == strconv.Itoa(i+1) {
+				chosen[option.value] = true
+				found = true
+			}
+		}
+		if !found {
+			return "", fmt.Errorf("%s is not one of the options", part)
+		}
+	}
+	if !multi && len(chosen) == 0 {
+		return "", fmt.Errorf("choose one of the options")
+	}
+
+	values := make([]string, 0)
+	for _, option := range options {
+		if chosen[option.value] {
+			values = append(values, option.value)
+		}
+	}
+	return strings.Join(values, ","), nil
+}
+
+func requestChoice(value *string, description string, options []MenuOption) {
+	requestMenu(value, description, options, false)
+}
+
+func requestMultiChoice(value *string, description string, options []MenuOption) {
+	requestMenu(value, description, options, true)
+}
+
+// otherOption lets the user type a value of an open choice.
+const otherOption = "(other)"
+
+// requestOpenChoice offers options but accepts any other value as well, like
+// a version Nexus does not know yet. The current value is offered first.
+func requestOpenChoice(value *string, description string, options []MenuOption) {
+	if args.nonInteractive || len(options) == 0 {
+		requestInput(value, description)
+		return
+	}
+	current := *value
+	found := current == ""
+	for _, option := range options {
+		found = found || option.value == current
+	}
+	if !found {
+		options = append([]MenuOption{{current, "current"}}, options...)
+	}
+	options = append(options, MenuOption{otherOption, "enter another value"})
+
+	requestChoice(value, description, options)
+	if *value == otherOption {
+		*value = current
+		requestInput(value, strings.TrimRight(description, " \n")+"\nEnter the value:")
+	}
+}
+
+func requestMenu(value *string, description string, options []MenuOption, multi bool) {
+	description = strings.TrimRight(description, " \n")
+	if args.nonInteractive {
+		requestInput(value, description)
+		answer, err := parseMenuAnswer(*value, options, multi)
+		if err != nil {
+			log.Fatalf("%s: %s", strings.TrimSpace(description), err)
+		}
+		*value = answer
+		return
+	}
+
+	if supportsArrowKeys() {
+		if answer, ok := runArrowKeyMenu(description, newMenu(options, multi, *value)); ok {
+			*value = answer
+			log.Debug("Value chosen: %v", answer)
+			return
+		}
+	}
+
+	// numbered fallback for terminals which cannot handle the menu
+	hint := "Enter the number or the value."
+	if multi {
+		hint = "Enter numbers or values separated by commas."
+	}
+	text := description + "\n"
+	for i, option := range options {
+		text += fmt.Sprintf(" %2d) %s\n", i+1, menuOptionText(option))
+	}
+	for {
+		answer := *value
+		requestInput(&answer, text+hint)
+		if isNavigation(strings.TrimSpace(answer)) {
+			*value = strings.TrimSpace(answer)
+			return
+		}
+		parsed, err := parseMenuAnswer(answer, options, multi)
+		if err == nil {
+			*value = parsed
+			return
+		}
+		log.Error("%s", err)
+	}
+}
+
+// supportsArrowKeys is true for an interactive terminal on Unix which stty
+// can switch to reading single keys.
+func supportsArrowKeys() bool {
+	if runtime.GOOS == "windows" {
+		return false
+	}
+	if term := os.Getenv("TERM"); term == "" || term == "dumb" {
+		return false
+	}
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	_, err = exec.LookPath("stty")
+	return err == nil
+}
+
+func stty(sttyArgs ...string) (string, error) {
+	cmd := exec.Command("stty", sttyArgs...)
+	cmd.Stdin = os.Stdin
+	output, err := cmd.Output()
+	return strings.TrimSpace(string(output)), err
+}
+
+// runArrowKeyMenu switches the terminal to single keys without echo, keeping
+// Ctrl+C working, and draws the menu until enter is pressed.
+func runArrowKeyMenu(description string, menu *Menu) (string, bool) {
+	state, err := stty("-g")
+	if err != nil {
+		return "", false
+	}
+	if _, err := stty("-icanon", "-echo", "min", "1"); err != nil {
+		return "", false
+	}
+	restore := func() { stty(state) }
+	removeCleanup := addCleanup(restore)
+	defer removeCleanup()
+	defer restore()
+
+	log.Warning(description)
+	hint := "Use the arrow keys or numbers and press enter, left goes back."
+	if menu.multi {
+		hint = "Use the arrow keys or numbers, space to select and press enter, left goes back."
+	}
+	log.Info("%s", hint)
+
+	output := promptOutput()
+	lines := menu.lines()
+	fmt.Fprint(output, strings.Join(lines, "\n")+"\n")
+	for {
+		key, err := readKey()
+		if err != nil {
+			return "", false
+		}
+		if key == "back" {
+			fmt.Fprintln(output, navigateBack)
+			return navigateBack, true
+		}
+		done := menu.handle(key)
+
+		// move up and draw the options again
+		fmt.Fprintf(output, "\033[%dA", len(lines))
+		lines = menu.lines()
+		for _, line := range lines {
+			fmt.Fprint(output, "\033[2K"+line+"\n")
+		}
+		if done {
+			return menu.value(), true
+		}
+	}
+}
+
+// bytes read ahead by readKey which belong to the next key
+var pendingKeyBytes []byte
+
+func readKeyByte() (byte, error) {
+	if len(pendingKeyBytes) > 0 {
+		key := pendingKeyBytes[0]
+		pendingKeyBytes = pendingKeyBytes[1:]
+		return key, nil
+	}
+	buffer := make([]byte, 1)
+	if _, err := os.Stdin.Read(buffer); err != nil {
+		return 0, err
+	}
+	return buffer[0], nil
+}
+
+// readKey reads one key press and names the keys the menu knows. A lone ESC
+// and unknown escape sequences are ignored by returning "".
+func readKey() (string, error) {
+	key, err := readKeyByte()
+	if err != nil {
+		return "", err
+	}
+	switch key {
+	case '\r', '\n':
+		return "enter", nil
+	case ' ':
+		return "space", nil
+	case 'k':
+		return "up", nil
+	case 'j':
+		return "down", nil
+	case 'h', 127:
+		return "back", nil
+	case 27:
+		return readEscapeSequence()
+	}
+	return string(key), nil
+}
+
+// readEscapeSequence reads the rest of ESC [ ... or ESC O ... up to its final
+// byte. Anything else after ESC is kept for the next readKey.
+func readEscapeSequence() (string, error) {
+	next, err := readKeyByte()
+	if err != nil {
+		return "", nil
+	}
+	if next != '[' && next != 'O' {
+		pendingKeyBytes = append(pendingKeyBytes, next)
+		return "", nil
+	}
+	for {
+		final, err := readKeyByte()
+		if err != nil {
+			return "", err
+		}
+		// parameters like the 3 of ESC [ 3 ~ come before the final byte
+		if final < 0x40 || final > 0x7e {
+			continue
+		}
+		switch final {
+		case 'A':
+			return "up", nil
+		case 'B':
+			return "down", nil
+		case 'D':
+			return "back", nil
+		}
+		return "", nil
+	}
+}
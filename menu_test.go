@@ -0,0 +1,86 @@
+package main
+
+import (
+	. "github.com/franela/goblin"
+	. "github.com/onsi/gomega"
+	"os"
+	"testing"
+)
+
+func TestMenu(t *testing.T) {
+	g := Goblin(t)
+
+	RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	options := []MenuOption{{"forms", ""}, {"labels", ""}, {"reports", "BIRT"}}
+
+	g.Describe("Navigating a menu", func() {
+		g.It("Should start at the current value and wrap around", func() {
+			menu := newMenu(options, false, "labels")
+			Expect(menu.value()).Should(Equal("labels"))
+			menu.handle("down")
+			menu.handle("down")
+			Expect(menu.value()).Should(Equal("forms"))
+			menu.handle("up")
+			Expect(menu.handle("enter")).Should(BeTrue())
+			Expect(menu.value()).Should(Equal("reports"))
+		})
+		g.It("Should toggle options in a multi-choice menu", func() {
+			menu := newMenu(options, true, "reports,forms")
+			Expect(menu.value()).Should(Equal("forms,reports"))
+			menu.handle("1")
+			menu.handle("down")
+			menu.handle("space")
+			Expect(menu.value()).Should(Equal("labels,reports"))
+			Expect(menu.lines()[2]).Should(Equal("  [x] 3) reports (BIRT)"))
+		})
+	})
+
+	g.Describe("Parsing typed answers", func() {
+		g.It("Should accept numbers and values", func() {
+			Expect(parseMenuAnswer("2", options, false)).Should(Equal("labels"))
+			Expect(parseMenuAnswer("reports, 1", options, true)).Should(Equal("forms,reports"))
+			Expect(parseMenuAnswer("", options, true)).Should(Equal(""))
+		})
+		g.It("Should refuse anything else", func() {
+			_, err := parseMenuAnswer("4", options, false)
+			Expect(err).ShouldNot(BeNil())
+			_, err = parseMenuAnswer("", options, false)
+			Expect(err).ShouldNot(BeNil())
+		})
+	})
+
+	g.Describe("Reading keys", func() {
+		readKeys := func(input string) []string {
+			reader, writer, err := os.Pipe()
+			Expect(err).Should(BeNil())
+			writer.WriteString(input)
+			writer.Close()
+			stdin := os.Stdin
+			os.Stdin = reader
+			defer func() {
+				os.Stdin = stdin
+				reader.Close()
+			}()
+
+			keys := make([]string, 0)
+			for {
+				key, err := readKey()
+				if err != nil {
+					return keys
+				}
+				keys = append(keys, key)
+			}
+		}
+
+		g.It("Should name arrow keys", func() {
+			Expect(readKeys("\x1b[A\x1b[B\x1b[D\x1bOA\r")).Should(Equal([]string{"up", "down", "back", "up", "enter"}))
+		})
+		g.It("Should ignore a lone ESC without losing the next key", func() {
+			Expect(readKeys("\x1bj\x1b\x1b[B2")).Should(Equal([]string{"", "down", "", "down", "2"}))
+		})
+		g.It("Should ignore unknown sequences", func() {
+			Expect(readKeys("\x1b[3~\x1b[1;5Ck")).Should(Equal([]string{"", "", "up"}))
+		})
+	})
+}
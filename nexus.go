@@ -0,0 +1,291 @@
+package main
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	defaultTasArtifact            = "com.topdesk:tas"
+	defaultSolutionPluginArtifact = "com.topdesk.gradle:solution-plugin"
+	defaultNexusUsernameKey       = "nexusUsername"
+	defaultNexusPasswordKey       = "nexusPassword"
+	// number of versions offered in the wizard
+	shownVersions = 10
+)
+
+// MavenMetadata is the part of maven-metadata.xml Solutionist needs.
+type MavenMetadata struct {
+	GroupId    string `xml:"groupId"`
+	ArtifactId string `xml:"artifactId"`
+	Versioning struct {
+		Latest   string   `xml:"latest"`
+		Release  string   `xml:"release"`
+		Versions []string `xml:"versions>version"`
+	} `xml:"versioning"`
+}
+
+var (
+	availableTasVersions            []string
+	availableSolutionPluginVersions []string
+	latestSolutionPluginVersion     string
+	templateSolutionPluginVersion   string
+	solutionPluginPattern           = regexp.MustCompile(`solution-plugin:([\w.\-]+)`)
+)
+
+// mavenMetadataUrl turns coordinates like com.topdesk:tas into the URL of
+// their maven-metadata.xml below nexusUrl.
+func mavenMetadataUrl(nexusUrl string, coordinates string) (string, error) {
+	parts := strings.Split(coordinates, ":")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", fmt.Errorf("expected group:artifact but got %q", coordinates)
+	}
+	if !strings.HasSuffix(nexusUrl, "/") {
+		nexusUrl += "/"
+	}
+	return nexusUrl + strings.Replace(parts[0], ".", "/", -1) + "/" + parts[1] + "/maven-metadata.xml", nil
+}
+
+func parseMavenMetadata(input []byte) (MavenMetadata, error) {
+	var metadata MavenMetadata
+	err := xml.Unmarshal(input, &metadata)
+	sort.SliceStable(metadata.Versioning.Versions, func(i, j int) bool {
+		return compareVersions(metadata.Versioning.Versions[i], metadata.Versioning.Versions[j]) < 0
+	})
+	return metadata, err
+}
+
+func fetchMavenMetadata(ctx context.Context, coordinates string) (MavenMetadata, error) {
+	metadataUrl, err := mavenMetadataUrl(profile.NexusUrl, coordinates)
+	if err != nil {
+		return MavenMetadata{}, err
+	}
+	input, err := readFromUrl(ctx, metadataUrl, args.username, args.password)
+	if err != nil {
+		return MavenMetadata{}, err
+	}
+	return parseMavenMetadata(input)
+}
+
+// releasedVersions drops snapshots, newest last.
+func releasedVersions(versions []string) []string {
+	released := make([]string, 0)
+	for _, version := range versions {
+		if !strings.HasSuffix(version, "-SNAPSHOT") {
+			released = append(released, version)
+		}
+	}
+	return released
+}
+
+// lookupNexusVersions fetches the available TAS and solution plugin versions.
+// Nexus being unavailable is not fatal.
+func lookupNexusVersions(ctx context.Context) {
+	log.Info("")
+	log.Info("> Looking up versions on Nexus")
+
+	metadata, err := fetchMavenMetadata(ctx, profile.TasArtifact)
+	if err != nil {
+		log.Warning("Could not look up TAS versions: %s", err)
+	} else {
+		availableTasVersions = releasedVersions(metadata.Versioning.Versions)
+		log.Notice("%d TAS versions available", len(availableTasVersions))
+	}
+
+	metadata, err = fetchMavenMetadata(ctx, profile.SolutionPluginArtifact)
+	if err != nil {
+		log.Warning("Could not look up the solution plugin version: %s", err)
+	} else {
+		availableSolutionPluginVersions = releasedVersions(metadata.Versioning.Versions)
+		latestSolutionPluginVersion = metadata.Versioning.Release
+		if latestSolutionPluginVersion == "" {
+			latestSolutionPluginVersion = metadata.Versioning.Latest
+		}
+		log.Notice("Latest solution plugin: %s", latestSolutionPluginVersion)
+	}
+}
+
+func latestTasVersion() string {
+	if len(availableTasVersions) == 0 {
+		return ""
+	}
+	return availableTasVersions[len(availableTasVersions)-1]
+}
+
+// suggestSolutionPluginVersion prefers the latest solution plugin on Nexus
+// over the one of the template, unless the template is newer.
+func suggestSolutionPluginVersion() string {
+	if compareVersions(templateSolutionPluginVersion, latestSolutionPluginVersion) > 0 {
+		return templateSolutionPluginVersion
+	}
+	return latestSolutionPluginVersion
+}
+
+// versionOptions offers the newest versions first, versions being sorted
+// oldest first.
+func versionOptions(versions []string) []QuestionOption {
+	options := make([]QuestionOption, 0, shownVersions)
+	for i := len(versions) - 1; i >= 0 && len(options) < shownVersions; i-- {
+		options = append(options, QuestionOption{Value: versions[i]})
+	}
+	if len(options) > 0 {
+		options[0].Description = "latest"
+	}
+	return options
+}
+
+func isKnownTasVersion(tasVersion string) bool {
+	if len(availableTasVersions) == 0 {
+		return true
+	}
+	for _, version := range availableTasVersions {
+		if version == tasVersion {
+			return true
+		}
+	}
+	return false
+}
+
+// detectSolutionPluginVersion reads the solution plugin version of the
+// downloaded template, which the wizard offers to replace.
+func detectSolutionPluginVersion() {
+	input, err := ioutil.ReadFile(args.dir + "/build.gradle")
+	if err != nil {
+		return
+	}
+	match := solutionPluginPattern.FindStringSubmatch(string(input))
+	if match == nil {
+		log.Warning("build.gradle does not use the solution plugin by version, solutionPluginVersion is ignored")
+		return
+	}
+	templateSolutionPluginVersion = match[1]
+	if compareVersions(templateSolutionPluginVersion, latestSolutionPluginVersion) < 0 {
+		log.Notice("build.gradle uses solution plugin %s, %s is available on Nexus", templateSolutionPluginVersion, latestSolutionPluginVersion)
+	}
+}
+
+// artifactCollisions describes why group:artifact in version must not be used.
+// An unknown artifact results in no collisions.
+func artifactCollisions(metadata MavenMetadata, version string) []string {
+	collisions := make([]string, 0)
+	if len(metadata.Versioning.Versions) == 0 {
+		return collisions
+	}
+	collisions = append(collisions, fmt.Sprintf("%s:%s already exists on Nexus with versions %s",
+		metadata.GroupId, metadata.ArtifactId, strings.Join(metadata.Versioning.Versions, ", ")))
+	if isReleased(metadata, version) {
+		collisions = append(collisions, fmt.Sprintf("Version %s has already been released", version))
+	}
+	return collisions
+}
+
+func isReleased(metadata MavenMetadata, version string) bool {
+	for _, released := range releasedVersions(metadata.Versioning.Versions) {
+		if released == version {
+			return true
+		}
+	}
+	return false
+}
+
+// checkArtifactCollision makes sure nobody overwrites another customer's
+// artifacts by reusing group and internalProjectName. The user may choose
+// another name and version or continue anyway. Without questions only a
+// released version stops, so published projects can be run on again.
+func checkArtifactCollision(ctx context.Context) {
+	for {
+		log.Info("")
+		log.Info("> Checking %s:%s on Nexus", gradle.group, gradle.internalProjectName)
+
+		metadata, err := fetchMavenMetadata(ctx, gradle.group+":"+gradle.internalProjectName)
+		if isNotFound(err) {
+			log.Notice("Artifact is not in use yet")
+			return
+		}
+		if err != nil {
+			log.Warning("Could not check the artifact on Nexus: %s", err)
+			return
+		}
+		if metadata.GroupId == "" {
+			metadata.GroupId, metadata.ArtifactId = gradle.group, gradle.internalProjectName
+		}
+
+		collisions := artifactCollisions(metadata, gradle.version)
+		if len(collisions) == 0 {
+			log.Notice("Artifact is not in use yet")
+			return
+		}
+		for _, collision := range collisions {
+			log.Warning("%s", collision)
+		}
+		if args.nonInteractive {
+			if isReleased(metadata, gradle.version) {
+				log.Fatalf("Choose another version for %s:%s", gradle.group, gradle.internalProjectName)
+			}
+			return
+		}
+		if requestConfirmation("Continue anyway? This may overwrite another project's artifacts.", false) {
+			return
+		}
+		wizardQuestion("internalProjectName").askOnly()
+		wizardQuestion("version").askOnly()
+	}
+}
+
+func gradleUserHome() string {
+	if home := os.Getenv("GRADLE_USER_HOME"); home != "" {
+		return home
+	}
+	return filepath.Join(userHomeDir(), ".gradle")
+}
+
+// checkNexusCredentials makes sure gradle.properties in GRADLE_USER_HOME
+// holds the Nexus credentials the solution plugin needs for publishing.
+// The password is stored nowhere else and the file is only readable by the user.
+func checkNexusCredentials() {
+	log.Info("")
+	log.Info("> Checking Nexus credentials in GRADLE_USER_HOME")
+
+	path := filepath.Join(gradleUserHome(), "gradle.properties")
+	properties, err := readProperties(path)
+	if err != nil {
+		log.Warning("Could not read %s: %s", path, err)
+		return
+	}
+
+	_, hasUsername := properties.get(profile.NexusUsernameKey)
+	_, hasPassword := properties.get(profile.NexusPasswordKey)
+	if hasUsername && hasPassword {
+		log.Notice("%s and %s found in %s", profile.NexusUsernameKey, profile.NexusPasswordKey, path)
+		return
+	}
+
+	log.Warning("%s lacks %s or %s, publishing to Nexus will fail", path, profile.NexusUsernameKey, profile.NexusPasswordKey)
+	if !requestConfirmation("Add them now?", true) {
+		return
+	}
+
+	username := args.username
+	password := args.password
+	requestInput(&username, "Nexus username:")
+	requestHiddenInput(&password, "Nexus password (leave empty to use your Helga password):")
+
+	properties.set(profile.NexusUsernameKey, username)
+	properties.set(profile.NexusPasswordKey, password)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		log.Critical("Could not create %s: %s", filepath.Dir(path), err)
+		return
+	}
+	if err := properties.write(path, 0600); err != nil {
+		log.Critical("Could not write to %s: %s", path, err)
+	} else {
+		log.Notice("Nexus credentials added to %s", path)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	. "github.com/franela/goblin"
+	. "github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNexus(t *testing.T) {
+	g := Goblin(t)
+
+	RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	g.Describe("Reading maven-metadata.xml", func() {
+		g.It("Should build the URL from the coordinates", func() {
+			url, err := mavenMetadataUrl("http://nexus/content/groups/public", "com.topdesk:tas")
+			Expect(err).Should(BeNil())
+			Expect(url).Should(Equal("http://nexus/content/groups/public/com/topdesk/tas/maven-metadata.xml"))
+
+			_, err = mavenMetadataUrl("http://nexus/", "tas")
+			Expect(err).ShouldNot(BeNil())
+		})
+		g.It("Should sort versions and skip snapshots", func() {
+			metadata, err := parseMavenMetadata([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<metadata>
+  <groupId>com.topdesk</groupId>
+  <artifactId>tas</artifactId>
+  <versioning>
+    <latest>5.10.0-SNAPSHOT</latest>
+    <release>5.9.1</release>
+    <versions>
+      <version>5.10.0-SNAPSHOT</version>
+      <version>5.9.1</version>
+      <version>5.5.1</version>
+    </versions>
+  </versioning>
+</metadata>`))
+			Expect(err).Should(BeNil())
+			Expect(metadata.Versioning.Release).Should(Equal("5.9.1"))
+			Expect(releasedVersions(metadata.Versioning.Versions)).Should(Equal([]string{"5.5.1", "5.9.1"}))
+		})
+	})
+
+	g.Describe("Offering versions in the wizard", func() {
+		g.It("Should offer the newest versions first", func() {
+			versions := make([]string, 0)
+			for minor := 0; minor < 12; minor++ {
+				versions = append(versions, fmt.Sprintf("5.%d.0", minor))
+			}
+			options := versionOptions(versions)
+			Expect(len(options)).Should(Equal(shownVersions))
+			Expect(options[0]).Should(Equal(QuestionOption{"5.11.0", "latest"}))
+			Expect(options[1]).Should(Equal(QuestionOption{"5.10.0", ""}))
+			Expect(versionOptions(nil)).Should(BeEmpty())
+		})
+		g.It("Should suggest the newer of the template's and the latest solution plugin", func() {
+			defer func() { templateSolutionPluginVersion, latestSolutionPluginVersion = "", "" }()
+			templateSolutionPluginVersion, latestSolutionPluginVersion = "1.4.0", "1.10.2"
+			Expect(suggestSolutionPluginVersion()).Should(Equal("1.10.2"))
+			latestSolutionPluginVersion = ""
+			Expect(suggestSolutionPluginVersion()).Should(Equal("1.4.0"))
+		})
+	})
+
+	g.Describe("Checking artifacts for collisions", func() {
+		metadata := MavenMetadata{GroupId: "com.topdesk.solution.customer", ArtifactId: "acme_portal"}
+		metadata.Versioning.Versions = []string{"1.0.0", "1.1.0-SNAPSHOT"}
+
+		g.It("Should report existing artifacts", func() {
+			Expect(artifactCollisions(metadata, "2.0.0")).Should(HaveLen(1))
+		})
+		g.It("Should report released versions", func() {
+			Expect(artifactCollisions(metadata, "1.0.0")).Should(HaveLen(2))
+		})
+		g.It("Should accept unknown artifacts", func() {
+			Expect(artifactCollisions(MavenMetadata{}, "1.0.0")).Should(BeEmpty())
+		})
+		g.It("Should only go on without questions if the version is not released", func() {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `<metadata><versioning><versions><version>1.0.0</version><version>1.1.0-SNAPSHOT</version></versions></versioning></metadata>`)
+			}))
+			savedArgs, savedProfile, savedGradle := args, profile, gradle
+			defer func() {
+				args, profile, gradle = savedArgs, savedProfile, savedGradle
+				ts.Close()
+			}()
+			args.nonInteractive = true
+			profile.NexusUrl = ts.URL
+			gradle = GradleConfig{group: "com.topdesk.solution.customer", internalProjectName: "acme_portal", version: "1.1.0-SNAPSHOT"}
+
+			checkArtifactCollision(context.Background())
+			Expect(gradle.internalProjectName).Should(Equal("acme_portal"))
+			Expect(isReleased(metadata, "1.0.0")).Should(BeTrue())
+			Expect(isReleased(metadata, "1.1.0-SNAPSHOT")).Should(BeFalse())
+		})
+	})
+}
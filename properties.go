@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Properties is a minimal editor for Java properties files which keeps
+// comments and ordering intact.
+type Properties struct {
+	lines []string
+}
+
+func parseProperties(content string) *Properties {
+	content = strings.Replace(content, "\r\n", "\n", -1)
+	content = strings.TrimSuffix(content, "\n")
+	if content == "" {
+		return &Properties{lines: make([]string, 0)}
+	}
+	return &Properties{lines: strings.Split(content, "\n")}
+}
+
+func readProperties(path string) (*Properties, error) {
+	input, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return parseProperties(""), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseProperties(string(input)), nil
+}
+
+func (p *Properties) String() string {
+	return strings.Join(p.lines, "\n") + "\n"
+}
+
+func (p *Properties) write(path string, mode os.FileMode) error {
+	if err := ioutil.WriteFile(path, []byte(p.String()), mode); err != nil {
+		return err
+	}
+	// WriteFile keeps the mode of existing files
+	return os.Chmod(path, mode)
+}
+
+func (p *Properties) find(key string) int {
+	for i, line := range p.lines {
+		trimmed := strings.TrimLeft(line, " \t\f")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "!") {
+			continue
+		}
+		name, _ := splitPropertyLine(trimmed)
+		if name == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func (p *Properties) get(key string) (string, bool) {
+	i := p.find(key)
+	if i < 0 {
+		return "", false
+	}
+	_, value := splitPropertyLine(strings.TrimLeft(p.lines[i], " \t\f"))
+	return value, true
+}
+
+func (p *Properties) set(key string, value string) {
+	entry := escapeProperty(key, true) + "=" + escapeProperty(value, false)
+	if i := p.find(key); i >= 0 {
+		p.lines[i] = entry
+		return
+	}
+	p.lines = append(p.lines, entry)
+}
+
+// splitPropertyLine splits at the first unescaped '=', ':' or whitespace and
+// unescapes key and value. Lines continued with a backslash are not supported.
+func splitPropertyLine(line string) (string, string) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.IndexByte("=: \t\f", line[i]) >= 0 {
+			end = i
+			break
+		}
+	}
+	key := line[:end]
+	rest := strings.TrimLeft(line[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+	return unescapeProperty(key), unescapeProperty(rest)
+}
+
+// escapeProperty escapes like java.util.Properties.store: files are read as
+// ISO-8859-1, so everything beyond ASCII becomes \uXXXX.
+func escapeProperty(text string, isKey bool) string {
+	var buffer bytes.Buffer
+	for i, char := range utf16.Encode([]rune(text)) {
+		switch {
+		case char == ' ' && (i == 0 || isKey):
+			buffer.WriteString(`\ `)
+		case char == '\t':
+			buffer.WriteString(`\t`)
+		case char == '\n':
+			buffer.WriteString(`\n`)
+		case char == '\r':
+			buffer.WriteString(`\r`)
+		case char == '\f':
+			buffer.WriteString(`\f`)
+		case strings.ContainsRune(`\=:#!`, rune(char)):
+			buffer.WriteByte('\\')
+			buffer.WriteByte(byte(char))
+		case char < 0x20 || char > 0x7e:
+			fmt.Fprintf(&buffer, `\u%04X`, char)
+		default:
+			buffer.WriteByte(byte(char))
+		}
+	}
+	return buffer.String()
+}
+
+func unescapeProperty(text string) string {
+	chars := make([]uint16, 0, len(text))
+	for i := 0; i < len(text); i++ {
+		if text[i] != '\\' || i+1 == len(text) {
+			// keep UTF-8 written by other tools
+			r, size := utf8.DecodeRuneInString(text[i:])
+			chars = append(chars, utf16.Encode([]rune{r})...)
+			i += size - 1
+			continue
+		}
+		i++
+		switch text[i] {
+		case 't':
+			chars = append(chars, '\t')
+		case 'n':
+			chars = append(chars, '\n')
+		case 'r':
+			chars = append(chars, '\r')
+		case 'f':
+			chars = append(chars, '\f')
+		case 'u':
+			if i+5 > len(text) {
+				chars = append(chars, 'u')
+				break
+			}
+			code, err := strconv.ParseUint(text[i+1:i+5], 16, 16)
+			if err != nil {
+				chars = append(chars, 'u')
+				break
+			}
+			chars = append(chars, uint16(code))
+			i += 4
+		default:
+			r, size := utf8.DecodeRuneInString(text[i:])
+			chars = append(chars, utf16.Encode([]rune{r})...)
+			i += size - 1
+		}
+	}
+	return string(utf16.Decode(chars))
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	. "github.com/franela/goblin"
+	. "github.com/onsi/gomega"
+	"testing"
+)
+
+func TestProperties(t *testing.T) {
+	g := Goblin(t)
+
+	RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	g.Describe("Editing gradle.properties", func() {
+		g.It("Should replace existing keys in place", func() {
+			properties := parseProperties("# proxy\nsystemProp.http.proxyHost=proxy\norg.gradle.java.home=/old\n")
+			properties.set("org.gradle.java.home", "/usr/lib/jvm/java-8")
+			Expect(properties.String()).Should(Equal("# proxy\nsystemProp.http.proxyHost=proxy\norg.gradle.java.home=/usr/lib/jvm/java-8\n"))
+		})
+		g.It("Should append missing keys", func() {
+			properties := parseProperties("")
+			properties.set("nexusUsername", "me")
+			Expect(properties.String()).Should(Equal("nexusUsername=me\n"))
+		})
+		g.It("Should read values separated by colons", func() {
+			value, found := parseProperties("nexusUsername : me\n").get("nexusUsername")
+			Expect(found).Should(BeTrue())
+			Expect(value).Should(Equal("me"))
+		})
+		g.It("Should escape passwords and read them back", func() {
+			password := ` #!s3cr\t=:pa55 ẞ€ 😀`
+			properties := parseProperties("")
+			properties.set("nexusPassword", password)
+			Expect(properties.String()).Should(Equal(`nexusPassword=\ \#\!s3cr\\t\=\:pa55 \u1E9E\u20AC \uD83D\uDE00` + "\n"))
+
+			value, found := parseProperties(properties.String()).get("nexusPassword")
+			Expect(found).Should(BeTrue())
+			Expect(value).Should(Equal(password))
+		})
+		g.It("Should unescape values written by Java", func() {
+			value, _ := parseProperties(`distributionUrl=https\://services.gradle.org/gradle-2.14-bin.zip`).get("distributionUrl")
+			Expect(value).Should(Equal("https://services.gradle.org/gradle-2.14-bin.zip"))
+			value, _ = parseProperties(`key\ with\:colon = caf\u00e9\tbar`).get("key with:colon")
+			Expect(value).Should(Equal("café\tbar"))
+		})
+	})
+}
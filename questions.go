@@ -0,0 +1,585 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Question is one question of the wizard. Section is project (top of
+// build.gradle), solution (the solution block), plugin (the solution plugin
+// version of the buildscript) or helga (the repository).
+// Type is text, choice, multichoice or boolean; booleans are written to
+// build.gradle without quotes. Help and Default may use ${placeholders}.
+// OptionsFrom names built-in options added to Options, AllowOther lets a
+// choice accept values which are not among the options.
+// DependsOn is "id", "id=value" or "id!=value"; the question is skipped if it
+// does not hold. Validator and Suggestion name built-in functions, Pattern is a
+// regular expression the answer must match. Hook names a built-in step which
+// runs before the question is asked the first time and may fill in several
+// answers at once, like looking up the customer.
+type Question struct {
+	Id          string           `json:"id"`
+	Section     string           `json:"section"`
+	Help        string           `json:"help"`
+	Type        string           `json:"type"`
+	Default     string           `json:"default"`
+	Options     []QuestionOption `json:"options"`
+	OptionsFrom string           `json:"optionsFrom"`
+	AllowOther  bool             `json:"allowOther"`
+	Validator   string           `json:"validator"`
+	Pattern     string           `json:"pattern"`
+	DependsOn   string           `json:"dependsOn"`
+	Suggestion  string           `json:"suggestion"`
+	Hook        string           `json:"hook"`
+}
+
+type QuestionOption struct {
+	Value       string `json:"value"`
+	Description string `json:"description"`
+}
+
+const defaultQuestions = `[
+  {"id": "version", "section": "project", "default": "1.0.0-SNAPSHOT", "validator": "required",
+   "help": "Version of the project, e.g: 1.0.0\nAdd -SNAPSHOT to indicate it is a work in progress"},
+  {"id": "group", "section": "project", "type": "choice", "default": "com.topdesk.solution.customer",
+   "help": "One of these depending on the type of your project:",
+   "options": [
+     {"value": "com.topdesk.solution.customer", "description": "for a TOPdesk client"},
+     {"value": "com.topdesk.solution.addon"},
+     {"value": "com.topdesk.solution.prototype"},
+     {"value": "com.topdesk.solution.tool", "description": "intended for internal use, not limited to consultancy"},
+     {"value": "com.topdesk.solution.lib", "description": "a jar not a bespoke zip"},
+     {"value": "com.topdesk.solution.event", "description": "like a look & feel for a world cup etc"},
+     {"value": "com.topdesk.solution.product"}
+   ]},
+  {"id": "description", "section": "project", "default": "Tool for customizing icons in the Self Service Desk",
+   "help": "Short description of the project"},
+  {"id": "customerReferenceNumber", "section": "solution", "hook": "customerLookup",
+   "help": "The customer reference number of the customer this project is created for.\nYou can find this on the customer card in TOPhelp."},
+  {"id": "customerName", "section": "solution", "default": "Customer Name",
+   "help": "Full name of the customer: will end up as part of the ZIP file's name."},
+  {"id": "projectFullName", "section": "solution", "default": "Project Name",
+   "help": "Full name of the project: will end up as part of the ZIP file's name."},
+  {"id": "internalProjectName", "section": "solution", "default": "customer-name_project-name", "suggestion": "internalProjectName",
+   "validator": "required", "pattern": "^[A-Za-z0-9._-]+$",
+   "help": "Used as artifact id for publishing to nexus. Use the format 'customer-name_project-name' if it's a\ncustomer project, otherwise use 'project-name', or 'project-name-x.x' if you release TOPdesk specific builds (e.g: for an add-on)."},
+  {"id": "tasVersion", "section": "solution", "type": "choice", "optionsFrom": "tasVersions", "allowOther": true,
+   "default": "5.5.1", "suggestion": "latestTasVersion", "validator": "tasVersion",
+   "help": "The TAS version you want to work on, e.g. 5.4.1"},
+  {"id": "isXfgProject", "section": "solution", "type": "boolean", "default": "false",
+   "help": "Set this to true if this project uses XFG forms. The zip will be locked automatically.\nThis also applies to TOPdesk 5.2+."},
+  {"id": "testCase", "section": "solution",
+   "help": "The test case id associated with this solution (used by TOPdesk's test team)."},
+  {"id": "uniqueId", "section": "solution", "suggestion": "uniqueId", "validator": "uniqueId",
+   "help": "A unique identifier for your Solution, mandatory when creating a zip. Use letters, digits and .-_: only,\nstarting with a letter or digit, at most 100 characters.\nSaaS will use this to match old and new versions, and it can also be used by the Portfolio.\nIt is automatically generated but you can choose to overwrite it.\nProjects Solutionist runs on again keep their uniqueId."},
+  {"id": "projectType", "section": "solution", "type": "multichoice", "default": "forms,lookandfeel,labels,reports,modifiedcards,xmlimport,addon,other",
+   "help": "It is not mandatory, but it there will be a warning if it isn’t filled in.\nThis makes sure we can categorize Solutions better in the future.",
+   "options": [
+     {"value": "forms"}, {"value": "lookandfeel"}, {"value": "labels"}, {"value": "reports"},
+     {"value": "modifiedcards"}, {"value": "xmlimport"}, {"value": "addon"}, {"value": "other"}
+   ]},
+  {"id": "solutionPluginVersion", "section": "plugin", "type": "choice", "optionsFrom": "solutionPluginVersions", "allowOther": true,
+   "suggestion": "latestSolutionPluginVersion", "pattern": "^[A-Za-z0-9._-]*$",
+   "help": "Version of the solution plugin build.gradle uses. The template's version is kept if this is empty."},
+  {"id": "name", "section": "helga", "suggestion": "helgaName", "validator": "required",
+   "help": "One of these depending on the type of your project:\n- customers/[reference-number]_[customer-name]/[project-name]\n- add-ons/[add-on-name]\n- prototypes/[prototype-name]\n- tools/[tool-project-name] (Tool, also used by nondevs, e.g. XFG, XIM)\n- resources/[internal-project-name] (Libraries go here)\n- events/[internal-project-name]\n- products/[internal-project-name]\n- sandbox/[username]/[project-name] (Playground/Apekooien)\n\nSuggestions are based on the chosen project group."}
+]`
+
+// wizardQuestions are the built-in questions plus those loadQuestions finds.
+var wizardQuestions = builtinQuestions()
+
+// questionValidators check an answer. An error makes the wizard ask again.
+var questionValidators = map[string]func(string) error{
+	"required": func(value string) error {
+		if strings.TrimSpace(value) == "" {
+			return fmt.Errorf("an answer is required")
+		}
+		return nil
+	},
+	"tasVersion": func(value string) error {
+		// Nexus may lag behind, so an unknown version is only a warning
+		if !isKnownTasVersion(value) {
+			log.Warning("TAS version %s does not exist on Nexus", value)
+		}
+		return nil
+	},
+	"uniqueId": checkUniqueId,
+}
+
+// questionSuggestions replace the default of a question the first time it is
+// asked. An empty suggestion keeps the default.
+var questionSuggestions = map[string]func(context.Context) string{
+	"internalProjectName": func(ctx context.Context) string {
+		if gradle.customerReferenceNumber == "" {
+			return ""
+		}
+		return slug(gradle.customerName) + "_" + slug(gradle.projectFullName)
+	},
+	"latestTasVersion":            func(ctx context.Context) string { return latestTasVersion() },
+	"latestSolutionPluginVersion": func(ctx context.Context) string { return suggestSolutionPluginVersion() },
+	"uniqueId":                    func(ctx context.Context) string { return suggestUniqueId() },
+	"helgaName":                   func(ctx context.Context) string { return suggestHelgaName() },
+}
+
+// questionHooks run before a question is asked the first time.
+var questionHooks = map[string]func(context.Context){
+	// other projects may name a customer too, but are not looked up
+	"customerLookup": func(ctx context.Context) {
+		if gradle.group == "com.topdesk.solution.customer" {
+			selectCustomer(ctx)
+		}
+	},
+}
+
+// questionOptionSources provide options known at runtime, like the versions
+// on Nexus.
+var questionOptionSources = map[string]func() []QuestionOption{
+	"tasVersions":            func() []QuestionOption { return versionOptions(availableTasVersions) },
+	"solutionPluginVersions": func() []QuestionOption { return versionOptions(availableSolutionPluginVersions) },
+}
+
+func builtinQuestions() []Question {
+	questions, err := parseQuestions([]byte(defaultQuestions))
+	if err != nil {
+		panic(err)
+	}
+	return questions
+}
+
+func parseQuestions(input []byte) ([]Question, error) {
+	var questions []Question
+	if err := json.Unmarshal(input, &questions); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for i, question := range questions {
+		if question.Id == "" {
+			return nil, fmt.Errorf("question %d has no id", i+1)
+		}
+		if seen[question.Id] {
+			return nil, fmt.Errorf("question %s is defined twice", question.Id)
+		}
+		seen[question.Id] = true
+		switch question.Section {
+		case "project", "solution", "plugin", "helga":
+		default:
+			return nil, fmt.Errorf("question %s has unknown section %q", question.Id, question.Section)
+		}
+		switch question.Type {
+		case "":
+			questions[i].Type = "text"
+		case "text", "choice", "multichoice", "boolean":
+		default:
+			return nil, fmt.Errorf("question %s has unknown type %q", question.Id, question.Type)
+		}
+		if question.Validator != "" && questionValidators[question.Validator] == nil {
+			return nil, fmt.Errorf("question %s has unknown validator %q", question.Id, question.Validator)
+		}
+		if question.Suggestion != "" && questionSuggestions[question.Suggestion] == nil {
+			return nil, fmt.Errorf("question %s has unknown suggestion %q", question.Id, question.Suggestion)
+		}
+		if question.Hook != "" && questionHooks[question.Hook] == nil {
+			return nil, fmt.Errorf("question %s has unknown hook %q", question.Id, question.Hook)
+		}
+		if question.OptionsFrom != "" && questionOptionSources[question.OptionsFrom] == nil {
+			return nil, fmt.Errorf("question %s has unknown optionsFrom %q", question.Id, question.OptionsFrom)
+		}
+		if question.AllowOther && question.Type != "choice" {
+			return nil, fmt.Errorf("question %s allows other values but is no choice", question.Id)
+		}
+		if _, err := regexp.Compile(question.Pattern); err != nil {
+			return nil, fmt.Errorf("question %s has an invalid pattern: %s", question.Id, err)
+		}
+	}
+	return questions, nil
+}
+
+// loadQuestions prefers a local file from the profile, then one shipped with
+// the build template and merges it into the built-in questions.
+func loadQuestions(ctx context.Context) {
+	input := loadDocTemplate(ctx, "questions.json", profile.QuestionsFile, "")
+	if input == "" {
+		return
+	}
+	questions, err := parseQuestions([]byte(input))
+	if err != nil {
+		log.Warning("Could not read questions.json, using the built-in questions: %s", err)
+		return
+	}
+	wizardQuestions = mergeQuestions(builtinQuestions(), questions)
+}
+
+// mergeQuestions replaces questions with the same id in place and adds new
+// ones after the last question of their section.
+func mergeQuestions(questions []Question, loaded []Question) []Question {
+	merged := append([]Question{}, questions...)
+	for _, question := range loaded {
+		if index := questionIndex(merged, question.Id); index >= 0 {
+			merged[index] = question
+			continue
+		}
+		position := len(merged)
+		for i, existing := range merged {
+			if existing.Section == question.Section {
+				position = i + 1
+			}
+		}
+		merged = append(merged[:position], append([]Question{question}, merged[position:]...)...)
+	}
+	return merged
+}
+
+func (q Question) options() []MenuOption {
+	if q.Type == "boolean" {
+		return []MenuOption{{"false", ""}, {"true", ""}}
+	}
+	questionOptions := q.Options
+	if q.OptionsFrom != "" {
+		questionOptions = append(append([]QuestionOption{}, q.Options...), questionOptionSources[q.OptionsFrom]()...)
+	}
+	options := make([]MenuOption, 0, len(questionOptions))
+	for _, option := range questionOptions {
+		options = append(options, MenuOption{option.Value, option.Description})
+	}
+	return options
+}
+
+func questionValue(q Question) string {
+	if q.Section == "helga" {
+		return helga.get(q.Id)
+	}
+	return gradle.get(q.Id)
+}
+
+func setQuestionValue(q Question, value string) {
+	if q.Section == "helga" {
+		helga.set(q.Id, value)
+	} else {
+		gradle.set(q.Id, value)
+	}
+}
+
+// wizardValues are available as ${placeholders} in help texts and defaults.
+func wizardValues() map[string]string {
+	values := gradleConfigValues()
+	values["name"] = helga.Name
+	values["username"] = args.username
+	return values
+}
+
+// applyQuestionDefaults sets the defaults of the questions of a section.
+func applyQuestionDefaults(section string) {
+	for _, question := range wizardQuestions {
+		if question.Section == section {
+			setQuestionValue(question, replacePlaceholders(question.Default, wizardValues()))
+		}
+	}
+}
+
+// isRelevant checks dependsOn against the answers given so far.
+func (q Question) isRelevant() bool {
+	if q.DependsOn == "" {
+		return true
+	}
+	values := wizardValues()
+	if parts := strings.SplitN(q.DependsOn, "!=", 2); len(parts) == 2 {
+		return values[strings.TrimSpace(parts[0])] != strings.TrimSpace(parts[1])
+	}
+	if parts := strings.SplitN(q.DependsOn, "=", 2); len(parts) == 2 {
+		return values[strings.TrimSpace(parts[0])] == strings.TrimSpace(parts[1])
+	}
+	return values[strings.TrimSpace(q.DependsOn)] != ""
+}
+
+func (q Question) validate(value string) error {
+	if q.Validator != "" {
+		if err := questionValidators[q.Validator](value); err != nil {
+			return err
+		}
+	}
+	if q.Pattern != "" && !regexp.MustCompile(q.Pattern).MatchString(value) {
+		return fmt.Errorf("%s does not match %s", value, q.Pattern)
+	}
+	return nil
+}
+
+// prepare runs the hook and applies the suggestion of a question.
+func (q Question) prepare(ctx context.Context) {
+	if q.Hook != "" {
+		questionHooks[q.Hook](ctx)
+	}
+	q.suggest(ctx)
+}
+
+// suggest applies the suggestion of a question, if it has one.
+func (q Question) suggest(ctx context.Context) {
+	if q.Suggestion == "" {
+		return
+	}
+	if suggestion := questionSuggestions[q.Suggestion](ctx); suggestion != "" {
+		setQuestionValue(q, suggestion)
+	}
+}
+
+// Answers starting with these move through the wizard instead.
+const (
+	navigateBack = "<"
+	navigateTo   = "@"
+)
+
+func isNavigation(answer string) bool {
+	return answer == navigateBack || strings.HasPrefix(answer, navigateTo)
+}
+
+// unescapeAnswer drops the backslash of an answer like \@home, which would
+// navigate without it.
+func unescapeAnswer(answer string) string {
+	if strings.HasPrefix(answer, `\`) && isNavigation(answer[1:]) {
+		return answer[1:]
+	}
+	return answer
+}
+
+// ask asks a question until the answer is valid and returns a navigation
+// command if one was given instead. In non-interactive mode the answer comes
+// from -set and an invalid one ends the program.
+func (q Question) ask() string {
+	for {
+		value := questionValue(q)
+		description := "\n" + strings.ToUpper(q.Id) + ":\n" + replacePlaceholders(q.Help, wizardValues()) + "\n    "
+		options := q.options()
+		switch {
+		case q.Type == "choice" && q.AllowOther:
+			requestOpenChoice(&value, description, options)
+		case (q.Type == "choice" || q.Type == "multichoice") && len(options) == 0:
+			// e.g. Nexus could not be reached
+			requestInput(&value, description)
+		case q.Type == "choice" || q.Type == "boolean":
+			requestChoice(&value, description, options)
+		case q.Type == "multichoice":
+			requestMultiChoice(&value, description, options)
+		default:
+			requestInput(&value, description)
+		}
+
+		value = strings.TrimSpace(value)
+		if !args.nonInteractive {
+			if isNavigation(value) {
+				return value
+			}
+			value = unescapeAnswer(value)
+		}
+		err := q.validate(value)
+		if err == nil {
+			setQuestionValue(q, value)
+			return ""
+		}
+		if args.nonInteractive {
+			log.Fatalf("%s: %s", q.Id, err)
+		}
+		log.Error("%s", err)
+	}
+}
+
+func questionIndex(questions []Question, id string) int {
+	for i, question := range questions {
+		if strings.EqualFold(question.Id, id) {
+			return i
+		}
+	}
+	return -1
+}
+
+// wizardQuestion returns the question with the given id, or a required text
+// question if there is none.
+func wizardQuestion(id string) Question {
+	if index := questionIndex(wizardQuestions, id); index >= 0 {
+		return wizardQuestions[index]
+	}
+	return Question{Id: id, Section: "project", Type: "text", Validator: "required"}
+}
+
+func questionsOf(sections []string) []Question {
+	questions := make([]Question, 0)
+	for _, question := range wizardQuestions {
+		if containsString(sections, question.Section) {
+			questions = append(questions, question)
+		}
+	}
+	return questions
+}
+
+// Wizard asks the questions of some sections and lets the user review them.
+type Wizard struct {
+	questions []Question
+	// value of each prepared question after its suggestion, to recognize
+	// answers the user kept
+	offered map[string]string
+}
+
+func newWizard(sections ...string) *Wizard {
+	return &Wizard{questions: questionsOf(sections), offered: make(map[string]string)}
+}
+
+// prepare runs hook and suggestion the first time a question is asked. Later
+// the suggestion is updated as long as the user kept it, so it follows the
+// answers it is based on.
+func (w *Wizard) prepare(ctx context.Context, q Question) {
+	offered, prepared := w.offered[q.Id]
+	if !prepared {
+		q.prepare(ctx)
+	} else if questionValue(q) == offered {
+		q.suggest(ctx)
+	} else {
+		return
+	}
+	w.offered[q.Id] = questionValue(q)
+}
+
+// find returns the index of a question the user may go to.
+func (w *Wizard) find(id string) (int, error) {
+	index := questionIndex(w.questions, id)
+	if index < 0 {
+		return -1, fmt.Errorf("there is no question %s", id)
+	}
+	if !w.questions[index].isRelevant() {
+		return -1, fmt.Errorf("%s does not apply to this project", w.questions[index].Id)
+	}
+	return index, nil
+}
+
+// run asks the questions in order. < goes back to the previous question, @id
+// jumps to a question and returns to where the jump started once it is
+// answered.
+func (w *Wizard) run(ctx context.Context) {
+	history := make([]int, 0)
+	returnTo := -1
+
+	for i := 0; i < len(w.questions); {
+		question := w.questions[i]
+		if !question.isRelevant() {
+			i++
+			continue
+		}
+		w.prepare(ctx, question)
+
+		navigation := question.ask()
+		switch {
+		case navigation == navigateBack:
+			if len(history) == 0 {
+				log.Error("This is the first question")
+				continue
+			}
+			i = history[len(history)-1]
+			history = history[:len(history)-1]
+		case strings.HasPrefix(navigation, navigateTo):
+			target, err := w.find(strings.TrimPrefix(navigation, navigateTo))
+			if err != nil {
+				log.Error("%s", err)
+				continue
+			}
+			if returnTo < 0 {
+				returnTo = i
+			}
+			i = target
+		default:
+			history = append(history, i)
+			if returnTo >= 0 {
+				i, returnTo = returnTo, -1
+			} else {
+				i++
+			}
+		}
+	}
+}
+
+// review lists the answers and lets the user change any of them until nothing
+// is entered.
+func (w *Wizard) review(ctx context.Context) {
+	if args.nonInteractive {
+		return
+	}
+	for {
+		summary := "\nREVIEW:\n"
+		for i, question := range w.questions {
+			if question.isRelevant() {
+				summary += fmt.Sprintf(" %2d) %-25s %s\n", i+1, question.Id, questionValue(question))
+			}
+		}
+		choice := ""
+		requestInput(&choice, summary+"Enter the number or name of an answer to change it, or nothing to continue.")
+		choice = strings.TrimPrefix(strings.TrimSpace(choice), navigateTo)
+		if choice == "" {
+			return
+		}
+
+		if number, err := strconv.Atoi(choice); err == nil && number >= 1 && number <= len(w.questions) {
+			choice = w.questions[number-1].Id
+		}
+		index, err := w.find(choice)
+		if err != nil {
+			log.Error("%s", err)
+			continue
+		}
+		relevant := w.relevant()
+		w.questions[index].askOnly()
+		w.refresh(ctx, relevant)
+	}
+}
+
+func (w *Wizard) relevant() map[string]bool {
+	relevant := make(map[string]bool)
+	for _, question := range w.questions {
+		relevant[question.Id] = question.isRelevant()
+	}
+	return relevant
+}
+
+// askOnly asks a question without going back or jumping.
+func (q Question) askOnly() {
+	for q.ask() != "" {
+		log.Error("Going back or jumping is not possible here, answer the question or press enter to keep the value")
+	}
+}
+
+// refresh follows a changed answer: questions which apply now are asked and
+// suggestions the user kept are updated.
+func (w *Wizard) refresh(ctx context.Context, relevantBefore map[string]bool) {
+	for _, question := range w.questions {
+		if !question.isRelevant() {
+			continue
+		}
+		if !relevantBefore[question.Id] {
+			log.Notice("%s applies now", question.Id)
+			w.prepare(ctx, question)
+			question.askOnly()
+			continue
+		}
+		value := questionValue(question)
+		w.prepare(ctx, question)
+		if changed := questionValue(question); changed != value {
+			log.Notice("%s changed to %s", question.Id, changed)
+		}
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+// gradleLiteral quotes a value for build.gradle; booleans stay as they are.
+func gradleLiteral(q Question, value string) string {
+	if q.Type == "boolean" {
+		return value
+	}
+	return "'" + strings.Replace(value, "'", "\\'", -1) + "'"
+}
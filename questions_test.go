@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	. "github.com/franela/goblin"
+	. "github.com/onsi/gomega"
+	"github.com/op/go-logging"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+const wizardTestQuestions = `[
+  {"id": "version", "section": "project", "default": "1.0.0", "validator": "required"},
+  {"id": "group", "section": "project", "type": "choice", "default": "addon",
+   "options": [{"value": "addon"}, {"value": "customer"}]},
+  {"id": "supportLevel", "section": "solution", "dependsOn": "group=customer", "default": "silver", "pattern": "^(silver|gold)$"},
+  {"id": "internalProjectName", "section": "solution", "validator": "required", "pattern": "^[a-z-]+$"}
+]`
+
+const navigationTestQuestions = `[
+  {"id": "version", "section": "project", "default": "1.0.0"},
+  {"id": "group", "section": "project", "default": "addon"},
+  {"id": "customerReferenceNumber", "section": "solution", "dependsOn": "group=customer"},
+  {"id": "customerName", "section": "solution", "dependsOn": "group=customer", "default": "ACME"},
+  {"id": "tasVersion", "section": "solution", "default": "5.5.1"},
+  {"id": "projectFullName", "section": "solution", "default": "Portal"},
+  {"id": "internalProjectName", "section": "solution", "default": "portal", "suggestion": "internalProjectName"}
+]`
+
+// runWizard answers wizardTestQuestions in non-interactive mode.
+func runWizard(answers Answers) {
+	questions, err := parseQuestions([]byte(wizardTestQuestions))
+	if err != nil {
+		panic(err)
+	}
+	wizardQuestions = questions
+	args.nonInteractive = true
+	args.answers = answers
+	setupDefaultGradleConfig()
+	newWizard("project", "solution").run(context.Background())
+}
+
+func TestQuestions(t *testing.T) {
+	// invalid answers end the program, so they are tried in a child process
+	if answers := os.Getenv("SOLUTIONIST_TEST_ANSWERS"); answers != "" {
+		parsed := Answers{}
+		for _, answer := range strings.Fields(answers) {
+			parsed.Set(answer)
+		}
+		runWizard(parsed)
+		return
+	}
+
+	g := Goblin(t)
+
+	RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	g.Describe("Reading questions", func() {
+		var ts *httptest.Server
+		shipped := ""
+		var savedProfile Profile
+		g.Before(func() {
+			ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/hg/gradle/solution-plugin/raw-file/tip/setup/template-questions.json" || shipped == "" {
+					w.WriteHeader(404)
+					return
+				}
+				fmt.Fprint(w, shipped)
+			}))
+			savedProfile = profile
+			profile.HelgaUrl = ts.URL + "/"
+		})
+		g.After(func() {
+			profile = savedProfile
+			wizardQuestions = builtinQuestions()
+			ts.Close()
+		})
+		g.It("Should use the questions shipped with the template", func() {
+			shipped = `[{"id": "version", "section": "project", "default": "2.0.0"}, {"id": "supportLevel", "section": "solution"}]`
+			loadQuestions(context.Background())
+			Expect(len(wizardQuestions)).Should(Equal(len(builtinQuestions()) + 1))
+			Expect(wizardQuestion("version").Default).Should(Equal("2.0.0"))
+			Expect(wizardQuestion("supportLevel").Type).Should(Equal("text"))
+			Expect(wizardQuestion("uniqueId").Validator).Should(Equal("uniqueId"))
+		})
+		g.It("Should replace questions in place and add new ones to their section", func() {
+			questions, _ := parseQuestions([]byte(`[{"id": "a", "section": "project"}, {"id": "b", "section": "solution"}, {"id": "c", "section": "helga"}]`))
+			loaded, _ := parseQuestions([]byte(`[{"id": "b", "section": "solution", "default": "x"}, {"id": "d", "section": "solution"}, {"id": "e", "section": "plugin"}]`))
+			merged := mergeQuestions(questions, loaded)
+			ids := make([]string, 0)
+			for _, question := range merged {
+				ids = append(ids, question.Id)
+			}
+			Expect(ids).Should(Equal([]string{"a", "b", "d", "c", "e"}))
+			Expect(merged[1].Default).Should(Equal("x"))
+			Expect(len(questions)).Should(Equal(3))
+		})
+		g.It("Should fall back to the built-in questions on an invalid file", func() {
+			wizardQuestions = builtinQuestions()
+			shipped = `[{"id": "version", "section": "project", "validator": "magic"}]`
+			loadQuestions(context.Background())
+			Expect(wizardQuestions).Should(Equal(builtinQuestions()))
+
+			shipped = `{"id": "version"`
+			loadQuestions(context.Background())
+			Expect(wizardQuestions).Should(Equal(builtinQuestions()))
+		})
+		g.It("Should keep the built-in questions if none are shipped", func() {
+			wizardQuestions = builtinQuestions()
+			shipped = ""
+			loadQuestions(context.Background())
+			Expect(wizardQuestions).Should(Equal(builtinQuestions()))
+		})
+		g.It("Should refuse unknown types, validators and suggestions", func() {
+			_, err := parseQuestions([]byte(`[{"id": "a", "section": "solution", "type": "date"}]`))
+			Expect(err).ShouldNot(BeNil())
+			_, err = parseQuestions([]byte(`[{"id": "a", "section": "solution", "validator": "magic"}]`))
+			Expect(err).ShouldNot(BeNil())
+			_, err = parseQuestions([]byte(`[{"id": "a", "section": "gradle"}]`))
+			Expect(err).ShouldNot(BeNil())
+		})
+	})
+
+	g.Describe("Answering questions non-interactively", func() {
+		var savedArgs CmdlineArgs
+		g.Before(func() {
+			savedArgs = args
+		})
+		g.After(func() {
+			args = savedArgs
+			wizardQuestions = builtinQuestions()
+		})
+		g.It("Should take the answers from -set and skip irrelevant questions", func() {
+			runWizard(Answers{"version": "2.0.0", "group": "2", "supportlevel": "gold", "internalprojectname": "acme-portal"})
+			Expect(gradle.version).Should(Equal("2.0.0"))
+			Expect(gradle.group).Should(Equal("customer"))
+			Expect(gradle.get("supportLevel")).Should(Equal("gold"))
+			Expect(gradle.internalProjectName).Should(Equal("acme-portal"))
+
+			runWizard(Answers{"supportlevel": "gold", "internalprojectname": "acme-portal"})
+			Expect(gradle.version).Should(Equal("1.0.0"))
+			Expect(gradle.group).Should(Equal("addon"))
+			Expect(gradle.get("supportLevel")).Should(Equal("silver"))
+		})
+		g.It("Should stop on answers which are invalid", func() {
+			for answers, message := range map[string]string{
+				"internalprojectname=Acme_Portal":        "does not match",
+				"group=customer supportlevel=platinum":   "does not match",
+				"version= internalprojectname=acme":      "an answer is required",
+				"group=partner internalprojectname=acme": "is not one of the options",
+			} {
+				cmd := exec.Command(os.Args[0], "-test.run=TestQuestions")
+				cmd.Env = append(os.Environ(), "SOLUTIONIST_TEST_ANSWERS="+answers)
+				output, err := cmd.CombinedOutput()
+				Expect(err).ShouldNot(BeNil())
+				Expect(string(output)).Should(ContainSubstring(message))
+			}
+		})
+	})
+
+	g.Describe("Writing build.gradle", func() {
+		g.It("Should write the answers of the solution plugin's properties", func() {
+			defer func() { wizardQuestions = builtinQuestions() }()
+			questions, err := parseQuestions([]byte(`[
+  {"id": "version", "section": "project", "default": "1.0.0"},
+  {"id": "description", "section": "project", "default": "It's new"},
+  {"id": "isXfgProject", "section": "solution", "type": "boolean", "default": "false"},
+  {"id": "supportLevel", "section": "solution", "default": "gold"}
+]`))
+			Expect(err).Should(BeNil())
+			wizardQuestions = questions
+			setupDefaultGradleConfig()
+
+			Expect(gradle.get("supportLevel")).Should(Equal("gold"))
+			part := createNewConfigPart()
+			Expect(part[3]).Should(Equal("version     '1.0.0'"))
+			Expect(part[4]).Should(Equal("description 'It\\'s new'"))
+			Expect(part[9]).Should(Equal("    isXfgProject false"))
+			Expect(part[10]).Should(Equal("    supportLevel 'gold'"))
+		})
+	})
+
+	g.Describe("Depending on other answers", func() {
+		g.It("Should compare with the answers given so far", func() {
+			gradle = GradleConfig{group: "com.topdesk.solution.addon"}
+			Expect(Question{DependsOn: "group=com.topdesk.solution.customer"}.isRelevant()).Should(BeFalse())
+			Expect(Question{DependsOn: "group!=com.topdesk.solution.customer"}.isRelevant()).Should(BeTrue())
+			Expect(Question{DependsOn: "testCase"}.isRelevant()).Should(BeFalse())
+		})
+		g.It("Should ask every project for its customer reference number", func() {
+			gradle = GradleConfig{group: "com.topdesk.solution.addon"}
+			Expect(wizardQuestion("customerReferenceNumber").isRelevant()).Should(BeTrue())
+		})
+	})
+
+	g.Describe("Navigating the wizard", func() {
+		g.It("Should recognize going back and jumping", func() {
+			Expect(isNavigation("<")).Should(BeTrue())
+			Expect(isNavigation("@tasVersion")).Should(BeTrue())
+			Expect(isNavigation("5.5.1")).Should(BeFalse())
+			Expect(isNavigation(`\@home`)).Should(BeFalse())
+			Expect(unescapeAnswer(`\@home`)).Should(Equal("@home"))
+			Expect(unescapeAnswer(`\<`)).Should(Equal("<"))
+			Expect(unescapeAnswer(`\d+`)).Should(Equal(`\d+`))
+		})
+		g.It("Should go back, jump, return and review answers", func() {
+			savedArgs := args
+			defer func() {
+				args = savedArgs
+				wizardQuestions = builtinQuestions()
+				stdinReader = bufio.NewReader(os.Stdin)
+				logging.Reset()
+			}()
+			questions, err := parseQuestions([]byte(navigationTestQuestions))
+			Expect(err).Should(BeNil())
+			wizardQuestions = questions
+			args.nonInteractive = false
+			stdinReader = bufio.NewReader(strings.NewReader(strings.Join([]string{
+				"2.0.0",         // version
+				"<",             // group
+				"",              // version, kept
+				"",              // group, kept
+				"@version",      // tasVersion
+				"3.0.0",         // version
+				"@customerName", // tasVersion, does not apply
+				"5.9.1",         // tasVersion
+				"",              // projectFullName
+				"",              // internalProjectName, nothing to suggest yet
+				"group",         // review
+				"customer",      // group
+				"1001",          // customerReferenceNumber applies now
+				"",              // customerName applies now
+				"",              // review
+			}, "\n") + "\n"))
+			memory := logging.NewMemoryBackend(1000)
+			logging.SetBackend(memory)
+
+			setupDefaultGradleConfig()
+			wizard := newWizard("project", "solution")
+			wizard.run(context.Background())
+			wizard.review(context.Background())
+
+			asked := make([]string, 0)
+			for node := memory.Head(); node != nil; node = node.Next() {
+				if key := questionKey(node.Record.Message()); node.Record.Level == logging.WARNING && key != "" {
+					asked = append(asked, key)
+				}
+			}
+			Expect(asked).Should(Equal([]string{"version", "group", "version", "group", "tasversion", "version",
+				"tasversion", "tasversion", "projectfullname", "internalprojectname",
+				"review", "group", "customerreferencenumber", "customername", "review"}))
+			Expect(gradle.version).Should(Equal("3.0.0"))
+			Expect(gradle.group).Should(Equal("customer"))
+			Expect(gradle.customerReferenceNumber).Should(Equal("1001"))
+			Expect(gradle.customerName).Should(Equal("ACME"))
+			Expect(gradle.tasVersion).Should(Equal("5.9.1"))
+			// the kept suggestion follows the customer
+			Expect(gradle.internalProjectName).Should(Equal("acme_portal"))
+		})
+		g.It("Should find questions by id ignoring case", func() {
+			questions := []Question{{Id: "version"}, {Id: "tasVersion"}}
+			Expect(questionIndex(questions, "tasversion")).Should(Equal(1))
+			Expect(questionIndex(questions, "unknown")).Should(Equal(-1))
+		})
+	})
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RegisteredProject is a project created by Solutionist, kept in the
+// registry so it can be found again.
+type RegisteredProject struct {
+	Directory    string    `json:"directory"`
+	HelgaName    string    `json:"helgaName"`
+	HelgaUrl     string    `json:"helgaUrl"`
+	Group        string    `json:"group"`
+	ArtifactId   string    `json:"artifactId"`
+	CustomerName string    `json:"customerName"`
+	TasVersion   string    `json:"tasVersion"`
+	UniqueId     string    `json:"uniqueId"`
+	Created      time.Time `json:"created"`
+}
+
+// the registry has one JSON object per line, so concurrent batch runs can
+// append without overwriting each other
+func registryPath() string {
+	return filepath.Join(solutionistHomeDir(), "projects.jsonl")
+}
+
+func (p RegisteredProject) fields() map[string]string {
+	return map[string]string{
+		"directory":    p.Directory,
+		"helganame":    p.HelgaName,
+		"helgaurl":     p.HelgaUrl,
+		"group":        p.Group,
+		"artifactid":   p.ArtifactId,
+		"customername": p.CustomerName,
+		"tasversion":   p.TasVersion,
+		"uniqueid":     p.UniqueId,
+	}
+}
+
+// matches filters like "acme" on any field or "group=addon" on one field,
+// ignoring case. All filters must match.
+func (p RegisteredProject) matches(filters []string) bool {
+	fields := p.fields()
+	for _, filter := range filters {
+		filter = strings.ToLower(filter)
+		parts := strings.SplitN(filter, "=", 2)
+		if len(parts) == 2 {
+			if !strings.Contains(strings.ToLower(fields[parts[0]]), parts[1]) {
+				return false
+			}
+			continue
+		}
+		found := false
+		for _, value := range fields {
+			if strings.Contains(strings.ToLower(value), filter) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func readRegistry() ([]RegisteredProject, error) {
+	file, err := os.Open(registryPath())
+	if os.IsNotExist(err) {
+		return []RegisteredProject{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	projects := make([]RegisteredProject, 0)
+	scanner := bufio.NewScanner(file)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var project RegisteredProject
+		if err := json.Unmarshal(scanner.Bytes(), &project); err != nil {
+			log.Warning("Skipping line %d of %s: %s", line, registryPath(), err)
+			continue
+		}
+		projects = append(projects, project)
+	}
+	return projects, scanner.Err()
+}
+
+func appendToRegistry(project RegisteredProject) error {
+	entry, err := json.Marshal(project)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(solutionistHomeDir(), 0700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(registryPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(entry, '\n'))
+	return err
+}
+
+// duplicateUniqueIds maps every uniqueId used by more than one project to
+// those projects.
+func duplicateUniqueIds(projects []RegisteredProject) map[string][]RegisteredProject {
+	byId := make(map[string][]RegisteredProject)
+	for _, project := range projects {
+		if project.UniqueId != "" {
+			byId[project.UniqueId] = append(byId[project.UniqueId], project)
+		}
+	}
+	for id, shared := range byId {
+		if len(shared) < 2 {
+			delete(byId, id)
+		}
+	}
+	return byId
+}
+
+// registerProject records the project that was just created.
+func registerProject() {
+	directory, err := filepath.Abs(args.dir)
+	if err != nil {
+		directory = args.dir
+	}
+	project := RegisteredProject{
+		Directory:    directory,
+		HelgaName:    helga.Name,
+		HelgaUrl:     helgaRepoUrl(),
+		Group:        gradle.group,
+		ArtifactId:   gradle.internalProjectName,
+		CustomerName: gradle.customerName,
+		TasVersion:   gradle.tasVersion,
+		UniqueId:     gradle.uniqueId,
+		Created:      time.Now(),
+	}
+
+	if projects, err := readRegistry(); err == nil {
+		for _, registered := range projects {
+			if registered.UniqueId == project.UniqueId && registered.Directory != project.Directory {
+				log.Warning("uniqueId %s is also used by %s (%s)", project.UniqueId, registered.HelgaName, registered.Directory)
+			}
+		}
+	}
+
+	if err := appendToRegistry(project); err != nil {
+		log.Warning("Could not add the project to %s: %s", registryPath(), err)
+	} else {
+		log.Debug("Project added to %s", registryPath())
+	}
+}
+
+func runList() {
+	projects, err := readRegistry()
+	if err != nil {
+		log.Fatalf("Could not read %s: %s", registryPath(), err)
+	}
+
+	matching := make([]RegisteredProject, 0)
+	for _, project := range projects {
+		if project.matches(args.commandArgs) {
+			matching = append(matching, project)
+		}
+	}
+	sort.SliceStable(matching, func(i, j int) bool { return matching[i].Created.Before(matching[j].Created) })
+
+	duplicates := duplicateUniqueIds(projects)
+	for _, project := range matching {
+		marker := " "
+		if _, found := duplicates[project.UniqueId]; found {
+			marker = "!"
+		}
+		log.Info("%s %s  %-45s %-35s %s", marker, project.Created.Format("2006-01-02"), project.HelgaName, project.ArtifactId, project.Directory)
+	}
+	log.Info("")
+	log.Notice("%d of %d projects", len(matching), len(projects))
+	if len(duplicates) > 0 {
+		log.Warning("Projects marked with ! share their uniqueId with another project, see 'solutionist show'")
+	}
+}
+
+func runShow() {
+	if len(args.commandArgs) != 1 {
+		log.Fatalf("Usage: solutionist show <helga name, artifact id or directory>")
+	}
+	name := args.commandArgs[0]
+
+	projects, err := readRegistry()
+	if err != nil {
+		log.Fatalf("Could not read %s: %s", registryPath(), err)
+	}
+
+	duplicates := duplicateUniqueIds(projects)
+	found := false
+	for _, project := range projects {
+		if project.HelgaName != name && project.ArtifactId != name && project.Directory != name && filepath.Base(project.Directory) != name {
+			continue
+		}
+		found = true
+		log.Info("")
+		log.Info("Directory:     %s", project.Directory)
+		log.Info("Helga name:    %s", project.HelgaName)
+		log.Info("Helga URL:     %s", project.HelgaUrl)
+		log.Info("Group:         %s", project.Group)
+		log.Info("Artifact id:   %s", project.ArtifactId)
+		log.Info("Customer:      %s", project.CustomerName)
+		log.Info("TAS version:   %s", project.TasVersion)
+		log.Info("uniqueId:      %s", project.UniqueId)
+		log.Info("Created:       %s", project.Created.Format(time.RFC1123))
+		for _, other := range duplicates[project.UniqueId] {
+			if other.Directory != project.Directory || !other.Created.Equal(project.Created) {
+				log.Warning("uniqueId is also used by %s (%s)", other.HelgaName, other.Directory)
+			}
+		}
+	}
+	if !found {
+		log.Fatalf("%s not found in %s", name, registryPath())
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	. "github.com/franela/goblin"
+	. "github.com/onsi/gomega"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	g := Goblin(t)
+
+	RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	acme := RegisteredProject{Directory: "/work/acme", HelgaName: "customers/1001_acme/portal", Group: "com.topdesk.solution.customer", ArtifactId: "acme_portal", UniqueId: "a"}
+	addon := RegisteredProject{Directory: "/work/addon", HelgaName: "add-ons/icons", Group: "com.topdesk.solution.addon", ArtifactId: "icons", UniqueId: "b"}
+	copied := RegisteredProject{Directory: "/work/copy", HelgaName: "sandbox/chuckn/copy", Group: "com.topdesk.solution.prototype", ArtifactId: "copy", UniqueId: "a"}
+
+	g.Describe("Filtering projects", func() {
+		g.It("Should match text on any field", func() {
+			Expect(acme.matches([]string{"ACME"})).Should(BeTrue())
+			Expect(addon.matches([]string{"acme"})).Should(BeFalse())
+			Expect(addon.matches([]string{})).Should(BeTrue())
+		})
+		g.It("Should match key=value on one field", func() {
+			Expect(acme.matches([]string{"group=customer", "portal"})).Should(BeTrue())
+			Expect(acme.matches([]string{"artifactId=icons"})).Should(BeFalse())
+		})
+	})
+
+	g.Describe("Finding duplicate uniqueIds", func() {
+		g.It("Should group projects sharing an id", func() {
+			duplicates := duplicateUniqueIds([]RegisteredProject{acme, addon, copied})
+			Expect(len(duplicates)).Should(Equal(1))
+			Expect(duplicates["a"]).Should(Equal([]RegisteredProject{acme, copied}))
+		})
+	})
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// EnvProposal is a value Solutionist suggests for a missing or broken env var.
+type EnvProposal struct {
+	key   string
+	value string
+}
+
+func javaSearchDirs() []string {
+	switch runtime.GOOS {
+	case "windows":
+		return []string{`C:\Program Files\Java`, `C:\Program Files (x86)\Java`}
+	case "darwin":
+		return []string{"/Library/Java/JavaVirtualMachines"}
+	default:
+		return []string{"/usr/lib/jvm", "/usr/java", "/opt"}
+	}
+}
+
+func gradleSearchDirs() []string {
+	dirs := make([]string, 0)
+	switch runtime.GOOS {
+	case "windows":
+		dirs = append(dirs, `C:\gradle`, `C:\Program Files\gradle`, `C:\tools`)
+	case "darwin":
+		dirs = append(dirs, "/usr/local/opt/gradle", "/opt/gradle")
+	default:
+		dirs = append(dirs, "/opt/gradle", "/opt", "/usr/share")
+	}
+	if currentUser, err := user.Current(); err == nil {
+		dirs = append(dirs, filepath.Join(currentUser.HomeDir, ".sdkman", "candidates", "gradle"))
+	}
+	return dirs
+}
+
+func subDirs(dir string) []string {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	dirs := make([]string, 0)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dirs = append(dirs, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return dirs
+}
+
+// discoverJdks returns all JDKs found in common locations, newest first.
+func discoverJdks() []JavaInstallation {
+	jdks := make([]JavaInstallation, 0)
+	for _, searchDir := range javaSearchDirs() {
+		for _, dir := range subDirs(searchDir) {
+			home := dir
+			if runtime.GOOS == "darwin" {
+				home = filepath.Join(dir, "Contents", "Home")
+			}
+			if java := probeJava("", home); java.err == nil {
+				jdks = append(jdks, java)
+			}
+		}
+	}
+	sort.SliceStable(jdks, func(i, j int) bool { return compareVersions(jdks[i].version, jdks[j].version) > 0 })
+	return jdks
+}
+
+func gradleExecutable(gradleHome string) string {
+	name := "gradle"
+	if runtime.GOOS == "windows" {
+		name = "gradle.bat"
+	}
+	return filepath.Join(gradleHome, "bin", name)
+}
+
+// discoverGradleHomes returns all Gradle distributions found in common
+// locations, newest first. The version is taken from the directory name.
+func discoverGradleHomes() []string {
+	homes := make([]string, 0)
+	for _, searchDir := range gradleSearchDirs() {
+		for _, dir := range subDirs(searchDir) {
+			if _, err := os.Stat(gradleExecutable(dir)); err == nil {
+				homes = append(homes, dir)
+			}
+		}
+	}
+	sort.SliceStable(homes, func(i, j int) bool {
+		return compareVersions(gradleHomeVersion(homes[i]), gradleHomeVersion(homes[j])) > 0
+	})
+	return homes
+}
+
+func gradleHomeVersion(gradleHome string) string {
+	return strings.TrimPrefix(filepath.Base(gradleHome), "gradle-")
+}
+
+func findJdk(jdks []JavaInstallation, major int) (JavaInstallation, bool) {
+	for _, jdk := range jdks {
+		if jdk.major == major {
+			return jdk, true
+		}
+	}
+	return JavaInstallation{}, false
+}
+
+func proposeEnvironment(jdks []JavaInstallation, gradleHomes []string) []EnvProposal {
+	proposals := make([]EnvProposal, 0)
+
+	if java := detectJava("JAVA_HOME"); java.err != nil && len(jdks) > 0 {
+		proposals = append(proposals, EnvProposal{"JAVA_HOME", jdks[0].home})
+	}
+	for _, major := range []int{6, 7, 8} {
+		key := fmt.Sprintf("JAVA_HOME_%d", major)
+		java := detectJava(key)
+		if java.err == nil && java.major == major {
+			continue
+		}
+		if jdk, found := findJdk(jdks, major); found {
+			proposals = append(proposals, EnvProposal{key, jdk.home})
+		}
+	}
+
+	gradleHome := os.Getenv("GRADLE_HOME")
+	if _, err := os.Stat(gradleExecutable(gradleHome)); (gradleHome == "" || err != nil) && len(gradleHomes) > 0 {
+		proposals = append(proposals, EnvProposal{"GRADLE_HOME", gradleHomes[0]})
+	}
+
+	if os.Getenv("GRADLE_USER_HOME") == "" {
+		if currentUser, err := user.Current(); err == nil {
+			proposals = append(proposals, EnvProposal{"GRADLE_USER_HOME", filepath.Join(currentUser.HomeDir, ".gradle")})
+		}
+	}
+	return proposals
+}
+
+func envSnippetPath() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(userHomeDir(), "solutionist-env.cmd")
+	}
+	return filepath.Join(userHomeDir(), ".solutionist-env.sh")
+}
+
+func createEnvSnippet(proposals []EnvProposal) string {
+	lines := make([]string, 0)
+	if runtime.GOOS == "windows" {
+		lines = append(lines, "@echo off", "rem Generated by Solutionist "+version)
+		for _, proposal := range proposals {
+			lines = append(lines, fmt.Sprintf(`setx %s "%s"`, proposal.key, proposal.value))
+		}
+		return strings.Join(lines, "\r\n") + "\r\n"
+	}
+	lines = append(lines, "# Generated by Solutionist "+version)
+	for _, proposal := range proposals {
+		lines = append(lines, fmt.Sprintf(`export %s="%s"`, proposal.key, proposal.value))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func repairEnvironment() {
+	proposals := proposeEnvironment(discoverJdks(), discoverGradleHomes())
+	if len(proposals) == 0 {
+		return
+	}
+
+	log.Info("")
+	log.Info("> Solutionist found values for missing environment variables:")
+	for _, proposal := range proposals {
+		log.Notice("%16s"+": %s", proposal.key, proposal.value)
+	}
+
+	snippetPath := envSnippetPath()
+	if !requestConfirmation("Write them to "+snippetPath+"?", true) {
+		return
+	}
+
+	if err := ioutil.WriteFile(snippetPath, []byte(createEnvSnippet(proposals)), 0755); err != nil {
+		log.Critical("Could not write to %s: %s", snippetPath, err)
+		return
+	}
+	for _, proposal := range proposals {
+		os.Setenv(proposal.key, proposal.value)
+	}
+
+	if runtime.GOOS == "windows" {
+		log.Notice("Run %s once to store them permanently", snippetPath)
+	} else {
+		log.Notice("Add 'source %s' to your shell profile to keep them", snippetPath)
+	}
+}
+
+// pinGradleJava writes org.gradle.java.home to gradle.properties in
+// GRADLE_USER_HOME so Gradle uses the JDK even if JAVA_HOME points elsewhere.
+// The project's gradle.properties is committed, so a local path has no place
+// there.
+func pinGradleJava(jdk JavaInstallation) {
+	path := filepath.Join(gradleUserHome(), "gradle.properties")
+	if !requestConfirmation(fmt.Sprintf("Pin Java %s (%s) for all Gradle builds in %s?", jdk.version, jdk.home, path), false) {
+		return
+	}
+
+	properties, err := readProperties(path)
+	if err != nil {
+		log.Critical("Could not read %s: %s", path, err)
+		return
+	}
+	properties.set("org.gradle.java.home", filepath.ToSlash(jdk.home))
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		log.Critical("Could not create %s: %s", filepath.Dir(path), err)
+		return
+	}
+	// the file may hold the Nexus credentials too
+	if err := properties.write(path, 0600); err != nil {
+		log.Critical("Could not write to %s: %s", path, err)
+	} else {
+		log.Notice("org.gradle.java.home set in %s", path)
+	}
+}
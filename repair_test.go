@@ -0,0 +1,83 @@
+package main
+
+import (
+	. "github.com/franela/goblin"
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestRepair(t *testing.T) {
+	g := Goblin(t)
+
+	RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	keys := []string{"JAVA_HOME", "JAVA_HOME_6", "JAVA_HOME_7", "JAVA_HOME_8", "GRADLE_HOME", "GRADLE_USER_HOME"}
+	saved := make(map[string]string)
+	g.Before(func() {
+		for _, key := range keys {
+			saved[key] = os.Getenv(key)
+		}
+	})
+	g.After(func() {
+		for _, key := range keys {
+			os.Setenv(key, saved[key])
+		}
+	})
+
+	g.Describe("Proposing environment variables", func() {
+		jdks := []JavaInstallation{{home: "/jvm/jdk8", version: "1.8.0_202", major: 8}, {home: "/jvm/jdk7", version: "1.7.0_80", major: 7}}
+		gradleHomes := []string{"/opt/gradle-4.10", "/opt/gradle-2.14"}
+
+		g.It("Should propose the newest JDK and Gradle for broken variables", func() {
+			for _, key := range keys {
+				os.Setenv(key, "")
+			}
+			os.Setenv("JAVA_HOME", "/does/not/exist")
+			os.Setenv("GRADLE_USER_HOME", "/home/gradle")
+
+			Expect(proposeEnvironment(jdks, gradleHomes)).Should(Equal([]EnvProposal{
+				{"JAVA_HOME", "/jvm/jdk8"},
+				{"JAVA_HOME_7", "/jvm/jdk7"},
+				{"JAVA_HOME_8", "/jvm/jdk8"},
+				{"GRADLE_HOME", "/opt/gradle-4.10"},
+			}))
+		})
+		g.It("Should keep a working GRADLE_HOME", func() {
+			gradleHome, _ := ioutil.TempDir("", "gradle")
+			defer os.RemoveAll(gradleHome)
+			os.MkdirAll(filepath.Join(gradleHome, "bin"), 0777)
+			ioutil.WriteFile(gradleExecutable(gradleHome), []byte(""), 0755)
+			os.Setenv("GRADLE_HOME", gradleHome)
+			os.Setenv("GRADLE_USER_HOME", "/home/gradle")
+
+			for _, proposal := range proposeEnvironment(nil, gradleHomes) {
+				Expect(proposal.key).ShouldNot(Equal("GRADLE_HOME"))
+			}
+		})
+		g.It("Should propose nothing without installations", func() {
+			for _, key := range keys {
+				os.Setenv(key, "")
+			}
+			os.Setenv("GRADLE_USER_HOME", "/home/gradle")
+
+			Expect(proposeEnvironment(nil, nil)).Should(BeEmpty())
+		})
+	})
+
+	g.Describe("Writing the environment snippet", func() {
+		g.It("Should set every proposal", func() {
+			snippet := createEnvSnippet([]EnvProposal{{"JAVA_HOME", "/jvm/jdk8"}, {"GRADLE_HOME", "/opt/gradle 4"}})
+			if runtime.GOOS == "windows" {
+				Expect(snippet).Should(Equal("@echo off\r\nrem Generated by Solutionist " + version + "\r\n" +
+					"setx JAVA_HOME \"/jvm/jdk8\"\r\nsetx GRADLE_HOME \"/opt/gradle 4\"\r\n"))
+			} else {
+				Expect(snippet).Should(Equal("# Generated by Solutionist " + version + "\n" +
+					"export JAVA_HOME=\"/jvm/jdk8\"\nexport GRADLE_HOME=\"/opt/gradle 4\"\n"))
+			}
+		})
+	})
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ScaffoldEntry describes what is created for a single projectType.
+// File contents may contain placeholders like ${customerName}.
+type ScaffoldEntry struct {
+	Directories []string          `json:"directories"`
+	Files       map[string]string `json:"files"`
+}
+
+// ScaffoldManifest maps a projectType to its scaffold.
+type ScaffoldManifest map[string]ScaffoldEntry
+
+// used if the template does not ship a scaffold.json
+var defaultScaffoldManifest = ScaffoldManifest{
+	"forms": {
+		Directories: []string{"src/main/forms"},
+	},
+	"lookandfeel": {
+		Directories: []string{"src/main/lookandfeel/css", "src/main/lookandfeel/images"},
+		Files: map[string]string{
+			"src/main/lookandfeel/css/custom.css": "/* Look and feel for ${customerName} - ${projectFullName} */\n",
+		},
+	},
+	"labels": {
+		Directories: []string{"src/main/labels"},
+		Files: map[string]string{
+			"src/main/labels/labels_en.properties": "# Labels for ${customerName} - ${projectFullName}\n",
+			"src/main/labels/labels_nl.properties": "# Labels for ${customerName} - ${projectFullName}\n",
+		},
+	},
+	"reports": {
+		Directories: []string{"src/main/reports"},
+	},
+	"modifiedcards": {
+		Directories: []string{"src/main/modifiedcards"},
+	},
+	"xmlimport": {
+		Directories: []string{"src/main/xmlimport"},
+	},
+	"addon": {
+		Directories: []string{"src/main/addon"},
+	},
+	"other": {
+		Directories: []string{"src/main/other"},
+	},
+}
+
+func gradleConfigValues() map[string]string {
+	values := make(map[string]string)
+	for key, value := range gradle.extra {
+		values[key] = value
+	}
+	for key, field := range gradle.fields() {
+		values[key] = *field
+	}
+	return values
+}
+
+func replacePlaceholders(content string, values map[string]string) string {
+	for key, value := range values {
+		content = strings.Replace(content, "${"+key+"}", value, -1)
+	}
+	return content
+}
+
+func selectedProjectTypes() []string {
+	projectTypes := make([]string, 0)
+	for _, projectType := range strings.Split(gradle.projectType, ",") {
+		projectType = strings.TrimSpace(projectType)
+		if projectType != "" {
+			projectTypes = append(projectTypes, projectType)
+		}
+	}
+	return projectTypes
+}
+
+func loadScaffoldManifest(ctx context.Context) ScaffoldManifest {
+	input, err := readFromUrl(ctx, templateUrl()+"scaffold.json", args.username, args.password)
+	if err != nil {
+		log.Debug("No scaffold manifest shipped with the template (%s), using built-in one", err)
+		return defaultScaffoldManifest
+	}
+
+	var manifest ScaffoldManifest
+	if err := json.Unmarshal(input, &manifest); err != nil {
+		log.Warning("Could not parse scaffold manifest, using built-in one: %s", err)
+		return defaultScaffoldManifest
+	}
+	return manifest
+}
+
+func scaffoldProject(ctx context.Context) {
+	log.Info("")
+	log.Info("> Scaffolding project types [%s]", gradle.projectType)
+
+	applyScaffold(loadScaffoldManifest(ctx), selectedProjectTypes(), args.dir, gradleConfigValues())
+}
+
+// applyScaffold never overwrites existing files. Directories get a .keep file
+// so they end up in the initial commit.
+func applyScaffold(manifest ScaffoldManifest, projectTypes []string, targetDir string, values map[string]string) {
+	for _, projectType := range projectTypes {
+		entry, found := manifest[projectType]
+		if !found {
+			log.Warning("No scaffold for project type [%s]", projectType)
+			continue
+		}
+
+		for _, dir := range entry.Directories {
+			path, err := scaffoldPath(targetDir, dir)
+			if err != nil {
+				log.Critical("Skipping scaffold directory: %s", err)
+				continue
+			}
+			if err := os.MkdirAll(path, 0777); err != nil {
+				log.Critical("Could not create %s: %s", dir, err)
+				continue
+			}
+			writeScaffoldFile(filepath.Join(path, ".keep"), "")
+		}
+
+		fileNames := make([]string, 0, len(entry.Files))
+		for fileName := range entry.Files {
+			fileNames = append(fileNames, fileName)
+		}
+		sort.Strings(fileNames)
+		for _, fileName := range fileNames {
+			path, err := scaffoldPath(targetDir, fileName)
+			if err != nil {
+				log.Critical("Skipping scaffold file: %s", err)
+				continue
+			}
+			if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+				log.Critical("Could not create directory for %s: %s", fileName, err)
+				continue
+			}
+			writeScaffoldFile(path, replacePlaceholders(entry.Files[fileName], values))
+		}
+	}
+}
+
+// scaffoldPath keeps the paths of a downloaded manifest inside targetDir.
+func scaffoldPath(targetDir string, name string) (string, error) {
+	name = filepath.FromSlash(name)
+	if filepath.IsAbs(name) || filepath.VolumeName(name) != "" || strings.HasPrefix(name, string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is not a relative path", name)
+	}
+	path := filepath.Join(targetDir, name)
+	relative, err := filepath.Rel(filepath.Clean(targetDir), path)
+	if err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside of %s", name, targetDir)
+	}
+	return path, nil
+}
+
+func writeScaffoldFile(path string, content string) {
+	if _, err := os.Stat(path); err == nil {
+		log.Debug("%s already exists, skipping", path)
+		return
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		log.Critical("Could not write to %s: %s", path, err)
+	} else {
+		log.Debug("%s created", path)
+	}
+}
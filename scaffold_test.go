@@ -0,0 +1,92 @@
+package main
+
+import (
+	. "github.com/franela/goblin"
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestScaffold(t *testing.T) {
+	g := Goblin(t)
+
+	RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	g.Describe("Scaffolding selected project types", func() {
+		targetFolder := "test_scaffold"
+		manifest := ScaffoldManifest{
+			"labels": {
+				Directories: []string{"src/main/labels"},
+				Files:       map[string]string{"src/main/labels/labels_en.properties": "# ${customerName}\n"},
+			},
+			"reports": {
+				Directories: []string{"src/main/reports"},
+			},
+		}
+
+		g.Before(func() {
+			os.MkdirAll(targetFolder+"/src/main/labels", 0777)
+			ioutil.WriteFile(targetFolder+"/src/main/labels/labels_en.properties", []byte("existing"), 0644)
+			applyScaffold(manifest, []string{"labels", "unknown"}, targetFolder, map[string]string{"customerName": "ACME"})
+		})
+		g.It("Should create directories of selected types only", func() {
+			_, err := os.Stat(targetFolder + "/src/main/labels/.keep")
+			Expect(err).Should(BeNil())
+			_, err = os.Stat(targetFolder + "/src/main/reports")
+			Expect(os.IsNotExist(err)).Should(BeTrue())
+		})
+		g.It("Should not overwrite existing files", func() {
+			content, _ := ioutil.ReadFile(targetFolder + "/src/main/labels/labels_en.properties")
+			Expect(string(content)).Should(Equal("existing"))
+		})
+		g.After(func() {
+			os.RemoveAll(targetFolder)
+		})
+	})
+
+	g.Describe("Scaffolding a manifest with paths outside the project", func() {
+		targetFolder := "test_scaffold_escape/project"
+		manifest := ScaffoldManifest{
+			"evil": {
+				Directories: []string{"../escaped", "src/../../escaped-too", "src/ok"},
+				Files:       map[string]string{"../.bashrc": "rm -rf ~", "src/../README.txt": "fine"},
+			},
+		}
+
+		g.Before(func() {
+			os.MkdirAll(targetFolder, 0777)
+			applyScaffold(manifest, []string{"evil"}, targetFolder, map[string]string{})
+		})
+		g.It("Should not write outside of the project", func() {
+			_, err := os.Stat("test_scaffold_escape/escaped")
+			Expect(os.IsNotExist(err)).Should(BeTrue())
+			_, err = os.Stat("test_scaffold_escape/escaped-too")
+			Expect(os.IsNotExist(err)).Should(BeTrue())
+			_, err = os.Stat("test_scaffold_escape/.bashrc")
+			Expect(os.IsNotExist(err)).Should(BeTrue())
+		})
+		g.It("Should still create paths inside of the project", func() {
+			_, err := os.Stat(targetFolder + "/src/ok/.keep")
+			Expect(err).Should(BeNil())
+			_, err = os.Stat(targetFolder + "/README.txt")
+			Expect(err).Should(BeNil())
+		})
+		g.It("Should reject absolute paths", func() {
+			_, err := scaffoldPath(targetFolder, "/etc/profile.d/evil.sh")
+			Expect(err).ShouldNot(BeNil())
+			_, err = scaffoldPath(targetFolder, "..")
+			Expect(err).ShouldNot(BeNil())
+		})
+		g.After(func() {
+			os.RemoveAll("test_scaffold_escape")
+		})
+	})
+
+	g.Describe("Replacing placeholders", func() {
+		g.It("Should replace known keys and keep unknown ones", func() {
+			result := replacePlaceholders("${customerName}/${unknown}", map[string]string{"customerName": "ACME"})
+			Expect(result).Should(Equal("ACME/${unknown}"))
+		})
+	})
+}
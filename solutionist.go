@@ -1,42 +1,88 @@
 package main
 
-// TODO:guess internal projectname from customer and project name
-// TODO:guess helga repo path from gradle group
-// TODO:try to fix environment if possible
 // TODO: check if target dir is empty
 
 import (
+	"context"
 	"github.com/op/go-logging"
+	"os"
 )
 
 const (
 	version = "1.0.1"
+	// module used for the output of external commands
+	cmdLogModule = "output"
 )
 
 var (
-	log    = logging.MustGetLogger("solutionist")
-	args   CmdlineArgs
-	gradle GradleConfig
-	helga  HelgaConfig
+	log     = logging.MustGetLogger("solutionist")
+	cmdLog  = logging.MustGetLogger(cmdLogModule)
+	args    CmdlineArgs
+	gradle  GradleConfig
+	helga   HelgaConfig
+	profile Profile
 )
 
 func main() {
 	args = parseCmdline()
 	setupLogging()
+	loadProfile()
 	showInfo()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go handleInterrupts(cancel)
+
+	switch args.command {
+	case "batch":
+		runBatch(ctx)
+	case "doctor":
+		runDoctor(ctx)
+	case "login":
+		runLogin(ctx)
+	case "list":
+		runList()
+	case "logout":
+		runLogout()
+	case "show":
+		runShow()
+	default:
+		createProject(ctx)
+	}
+}
+
+func createProject(ctx context.Context) {
+	startSummary()
 	checkEnvironment()
-	downloadGradleBuildTemplate()
+	loginToHelga(ctx)
+	checkNexusCredentials()
+	downloadGradleBuildTemplate(ctx)
+	loadQuestions(ctx)
+	lookupNexusVersions(ctx)
+	detectSolutionPluginVersion()
 	setupDefaultGradleConfig()
-	collectGradleConfig()
+	collectGradleConfig(ctx)
+	checkArtifactCollision(ctx)
 	patchGradleConfig()
-	executeCmd("gradle", `-p`+args.dir+``, "wrapper")
-	executeCmd("gradle", `-p`+args.dir+``, "init")
-	executeCmd("hg", "init", ``+args.dir+``)
-	executeCmd("hg", "addremove", ``+args.dir+``)
-	executeCmd("hg", "commit", `-m Start a new Gradle project`, ``+args.dir+``)
+	selectJavaForTasVersion()
 	setupDefaultHelgaConfig()
-	collectHelgaConfig()
-	createHelgaRepo()
+	collectHelgaConfig(ctx)
+	scaffoldProject(ctx)
+	writeProjectDocs(ctx)
+	createGradleWrapper(ctx)
+	executeGradle(ctx, "init")
+	verifyGradleBuild(ctx)
+	executeCmd(ctx, "hg", "init", ``+args.dir+``)
+	writeIgnoreFiles()
+	executeCmd(ctx, "hg", "addremove", ``+args.dir+``)
+	executeCmd(ctx, "hg", "commit", `-m Start a new Gradle project`, ``+args.dir+``)
+	if createHelgaRepo(ctx) {
+		registerProject()
+	}
+	if finishSummary("success") != "success" {
+		log.Error("The project was created with errors, see %s", summaryFileName)
+		os.Exit(1)
+	}
 }
 
 func showInfo() {
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	. "github.com/franela/goblin"
 	. "github.com/onsi/gomega"
@@ -46,7 +47,7 @@ func TestDownloadBuildGradle(t *testing.T) {
 		})
 		g.It("Should download a build.gradle", func() {
 			url := ts.URL + "/download_with_basic_auth"
-			downloadFromUrl(url, targetFolder, "build.gradle", username, password)
+			downloadFromUrl(context.Background(), url, targetFolder, "build.gradle", username, password)
 			_, err := os.Stat(targetFolder + "/build.gradle")
 			Expect(err).Should(BeNil())
 
@@ -59,5 +60,21 @@ func TestDownloadBuildGradle(t *testing.T) {
 			ts.Close()
 		})
 	})
+}
+
+func TestCompareVersions(t *testing.T) {
+	g := Goblin(t)
+
+	RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
 
+	g.Describe("Comparing versions", func() {
+		g.It("Should compare numerically", func() {
+			Expect(compareVersions("5.10", "5.9")).Should(Equal(1))
+			Expect(compareVersions("2.14.1", "3.0")).Should(Equal(-1))
+		})
+		g.It("Should treat missing parts and qualifiers as equal", func() {
+			Expect(compareVersions("5.5", "5.5.0")).Should(Equal(0))
+			Expect(compareVersions("1.0.0-SNAPSHOT", "1.0.0")).Should(Equal(0))
+		})
+	})
 }
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/op/go-logging"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const summaryFileName = "solutionist-result.json"
+
+// RunSummary describes the outcome of creating a project for provisioning
+// scripts. Status is running, success, failed or interrupted.
+type RunSummary struct {
+	Status           string            `json:"status"`
+	Errors           []string          `json:"errors"`
+	Version          string            `json:"solutionistVersion"`
+	Directory        string            `json:"directory"`
+	Gradle           map[string]string `json:"gradle"`
+	HelgaUrl         string            `json:"helgaUrl"`
+	TemplateRevision string            `json:"templateRevision"`
+	Commands         []CommandRecord   `json:"commands"`
+	Started          time.Time         `json:"started"`
+	Finished         *time.Time        `json:"finished,omitempty"`
+}
+
+// CommandRecord is an executed external command. Status is ok, failed,
+// timeout or cancelled.
+type CommandRecord struct {
+	Command    string  `json:"command"`
+	WorkingDir string  `json:"workingDir"`
+	ExitCode   int     `json:"exitCode"`
+	Seconds    float64 `json:"seconds"`
+	Status     string  `json:"status"`
+}
+
+var (
+	summaryMutex     sync.Mutex
+	summary          = RunSummary{Status: "running", Errors: []string{}, Commands: []CommandRecord{}}
+	summaryEnabled   bool
+	templateRevision string
+)
+
+// startSummary makes the run write its summary: every critical error and the
+// end of the run update solutionist-result.json in the project directory, so
+// the file is there even if Solutionist stops on an error.
+func startSummary() {
+	summaryMutex.Lock()
+	summaryEnabled = true
+	summary.Version = version
+	summary.Started = time.Now()
+	summaryMutex.Unlock()
+
+	addCleanup(func() { finishSummary("interrupted") })
+}
+
+func recordCommand(record CommandRecord) {
+	summaryMutex.Lock()
+	defer summaryMutex.Unlock()
+	summary.Commands = append(summary.Commands, record)
+}
+
+func recordHelgaUrl(url string) {
+	summaryMutex.Lock()
+	defer summaryMutex.Unlock()
+	summary.HelgaUrl = url
+}
+
+// recordError keeps a critical message. Fatal errors end the program right
+// after logging, so the summary is written immediately.
+func recordError(message string) {
+	summaryMutex.Lock()
+	defer summaryMutex.Unlock()
+	summary.Errors = append(summary.Errors, message)
+	if summaryEnabled && summary.Finished == nil {
+		summary.Status = "failed"
+		writeSummary()
+	}
+}
+
+// finishSummary ends the run with status and returns the status written. A
+// run which logged critical errors is never a success, even if it went on.
+func finishSummary(status string) string {
+	summaryMutex.Lock()
+	defer summaryMutex.Unlock()
+	if !summaryEnabled {
+		return status
+	}
+	if status == "success" && len(summary.Errors) > 0 {
+		status = "failed"
+	}
+	summary.Status = status
+	finished := time.Now()
+	summary.Finished = &finished
+	writeSummary()
+
+	if args.output == "json" {
+		output, _ := json.MarshalIndent(summary, "", "  ")
+		fmt.Println(string(output))
+	}
+	return status
+}
+
+// writeSummary expects summaryMutex to be held.
+func writeSummary() {
+	summary.Gradle = gradleConfigValues()
+	summary.TemplateRevision = templateRevision
+	if absolute, err := filepath.Abs(args.dir); err == nil {
+		summary.Directory = absolute
+	}
+
+	output, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return
+	}
+	// logging here would call recordError again
+	ioutil.WriteFile(args.dir+"/"+summaryFileName, append(output, '\n'), 0644)
+}
+
+// summaryBackend collects critical log messages for the summary.
+type summaryBackend struct{}
+
+func (b summaryBackend) Log(level logging.Level, calldepth int, record *logging.Record) error {
+	if level == logging.CRITICAL {
+		recordError(record.Message())
+	}
+	return nil
+}
+
+// resolveTemplateRevision looks up the changeset tip currently points at, so
+// all template files come from the same revision and the summary can name it.
+func resolveTemplateRevision(ctx context.Context) {
+	res, err := httpRequest(ctx, "GET", helgaHgUrl()+"gradle/solution-plugin/raw-rev/tip", args.username, args.password, "", nil)
+	if err != nil {
+		log.Warning("Could not look up the template revision, using tip: %s", err)
+		return
+	}
+	defer res.Body.Close()
+	if res.StatusCode != 200 {
+		log.Warning("Could not look up the template revision, using tip: %s", res.Status)
+		return
+	}
+
+	revision := parseNodeId(bufio.NewScanner(res.Body))
+	if revision == "" {
+		log.Warning("No revision found for the template, using tip")
+		return
+	}
+	templateRevision = revision
+	log.Notice("Template revision: %s", templateRevision)
+}
+
+// parseNodeId reads the header of an hg patch up to the "# Node ID" line.
+func parseNodeId(scanner *bufio.Scanner) string {
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "#") {
+			return ""
+		}
+		if strings.HasPrefix(line, "# Node ID ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "# Node ID "))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	. "github.com/franela/goblin"
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSummary(t *testing.T) {
+	g := Goblin(t)
+
+	RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	g.Describe("Resolving the template revision", func() {
+		g.It("Should read the node id from the patch header", func() {
+			patch := "# HG changeset patch\n# User chuckn\n# Date 1467200000 -7200\n#      Wed Jun 29 13:33:20 2016 +0200\n# Node ID 3f2a9c1d0b7e\n# Parent  0a1b2c3d4e5f\nUpdate template\n"
+			Expect(parseNodeId(bufio.NewScanner(strings.NewReader(patch)))).Should(Equal("3f2a9c1d0b7e"))
+		})
+		g.It("Should give up on anything else", func() {
+			Expect(parseNodeId(bufio.NewScanner(strings.NewReader("<html>\n# Node ID 3f2a9c1d0b7e\n")))).Should(Equal(""))
+		})
+	})
+
+	g.Describe("Finishing the summary", func() {
+		var dir string
+		var savedArgs CmdlineArgs
+		g.BeforeEach(func() {
+			dir, _ = ioutil.TempDir("", "solutionist-summary")
+			savedArgs = args
+			args.dir = dir
+			summary = RunSummary{Status: "running", Errors: []string{}, Commands: []CommandRecord{}}
+			summaryEnabled = true
+		})
+		g.AfterEach(func() {
+			summaryEnabled = false
+			args = savedArgs
+			os.RemoveAll(dir)
+		})
+		g.It("Should report success without errors", func() {
+			Expect(finishSummary("success")).Should(Equal("success"))
+		})
+		g.It("Should keep a failure logged by a step which went on", func() {
+			recordError("Something went wrong")
+			Expect(finishSummary("success")).Should(Equal("failed"))
+
+			input, err := ioutil.ReadFile(dir + "/" + summaryFileName)
+			Expect(err).Should(BeNil())
+			var written RunSummary
+			Expect(json.Unmarshal(input, &written)).Should(BeNil())
+			Expect(written.Status).Should(Equal("failed"))
+			Expect(written.Errors).Should(Equal([]string{"Something went wrong"}))
+		})
+	})
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"github.com/nu7hatch/gouuid"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// how deep build.gradle files are searched for below the workspace root
+const workspaceScanDepth = 4
+
+var (
+	// ends up between single quotes in build.gradle, so no quotes or spaces
+	uniqueIdPattern       = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._:-]{0,99}$`)
+	gradleUniqueIdPattern = regexp.MustCompile(`(?m)^\s*uniqueId\s*[(=]?\s*['"]([^'"]*)['"]`)
+	skippedWorkspaceDirs  = map[string]bool{".hg": true, ".git": true, ".gradle": true, "build": true, "node_modules": true, "out": true}
+	existingUniqueId      string
+	generatedUniqueId     string
+)
+
+func newUniqueId() string {
+	uuid4, err := uuid.NewV4()
+	if err != nil {
+		log.Error("Error while generating UUID: %s", err)
+		return ""
+	}
+	return uuid4.String()
+}
+
+func validateUniqueId(id string) error {
+	if !uniqueIdPattern.MatchString(id) {
+		return fmt.Errorf("uniqueId %q must start with a letter or digit, contain only letters, digits and .-_: and be at most 100 characters long", id)
+	}
+	return nil
+}
+
+// parseUniqueId returns the first uniqueId set in a build.gradle, which is the
+// one Solutionist generated; the template's part after it is commented out.
+func parseUniqueId(content string) string {
+	match := gradleUniqueIdPattern.FindStringSubmatch(content)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+func readUniqueId(path string) string {
+	input, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return parseUniqueId(string(input))
+}
+
+// rememberExistingUniqueId keeps the uniqueId of a project Solutionist runs on
+// again, before the build template replaces build.gradle. SaaS matches old and
+// new versions of a solution by this id, so it must not change.
+func rememberExistingUniqueId() {
+	existingUniqueId = readUniqueId(filepath.Join(args.dir, "build.gradle"))
+	if existingUniqueId == "" {
+		directory, _ := filepath.Abs(args.dir)
+		if projects, err := readRegistry(); err == nil {
+			for _, project := range projects {
+				if project.Directory == directory {
+					existingUniqueId = project.UniqueId
+				}
+			}
+		}
+	}
+	if existingUniqueId != "" {
+		log.Notice("Keeping uniqueId %s of the existing project", existingUniqueId)
+	}
+}
+
+func workspaceRoot() string {
+	if profile.WorkspaceRoot != "" {
+		return profile.WorkspaceRoot
+	}
+	directory, err := filepath.Abs(args.dir)
+	if err != nil {
+		return ""
+	}
+	return filepath.Dir(directory)
+}
+
+// findUniqueIdUsers lists the projects besides ownDir using id, from the
+// registry and from build.gradle files below root.
+func findUniqueIdUsers(id string, ownDir string, root string, projects []RegisteredProject) []string {
+	users := make([]string, 0)
+	seen := map[string]bool{ownDir: true}
+	for _, project := range projects {
+		if project.UniqueId == id && !seen[project.Directory] {
+			seen[project.Directory] = true
+			users = append(users, project.Directory)
+		}
+	}
+	if root == "" {
+		return users
+	}
+
+	rootDepth := strings.Count(filepath.Clean(root), string(os.PathSeparator))
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if path != root && (skippedWorkspaceDirs[info.Name()] || strings.Count(path, string(os.PathSeparator))-rootDepth > workspaceScanDepth) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		directory := filepath.Dir(path)
+		if info.Name() == "build.gradle" && !seen[directory] && readUniqueId(path) == id {
+			seen[directory] = true
+			users = append(users, directory)
+		}
+		return nil
+	})
+	return users
+}
+
+// uniqueIdProblem tells why id cannot be used: it is invalid or used by
+// another project.
+func uniqueIdProblem(id string) error {
+	if err := validateUniqueId(id); err != nil {
+		return err
+	}
+	projects, err := readRegistry()
+	if err != nil {
+		log.Warning("Could not read %s: %s", registryPath(), err)
+	}
+	ownDir, _ := filepath.Abs(args.dir)
+	if users := findUniqueIdUsers(id, ownDir, workspaceRoot(), projects); len(users) > 0 {
+		return fmt.Errorf("uniqueId %s is already used by %s", id, strings.Join(users, ", "))
+	}
+	return nil
+}
+
+// checkUniqueId validates the uniqueId answer. SaaS matches old and new
+// versions of a solution by this id, so the id of an existing project is kept
+// even if it is invalid or shared, and only replaced after confirmation.
+func checkUniqueId(id string) error {
+	if existingUniqueId == "" {
+		return uniqueIdProblem(id)
+	}
+	if id == existingUniqueId {
+		if err := uniqueIdProblem(id); err != nil {
+			log.Warning("Keeping the existing uniqueId, although %s", err)
+		}
+		return nil
+	}
+	if args.nonInteractive {
+		return fmt.Errorf("the existing project's uniqueId %s must not change", existingUniqueId)
+	}
+	if !requestConfirmation("Replace uniqueId "+existingUniqueId+" of the existing project? SaaS will no longer match its versions.", false) {
+		return fmt.Errorf("enter nothing to keep uniqueId %s", existingUniqueId)
+	}
+	return uniqueIdProblem(id)
+}
+
+// suggestUniqueId keeps the id of an existing project. A generated id is
+// suggested again when the wizard updates its suggestions.
+func suggestUniqueId() string {
+	if existingUniqueId != "" {
+		return existingUniqueId
+	}
+	if generatedUniqueId == "" {
+		generatedUniqueId = newUniqueId()
+	}
+	return generatedUniqueId
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	. "github.com/franela/goblin"
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUniqueId(t *testing.T) {
+	g := Goblin(t)
+
+	RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	g.Describe("Validating uniqueIds", func() {
+		g.It("Should accept UUIDs and simple names", func() {
+			Expect(validateUniqueId("0f8fad5b-d9cb-469f-a165-70867728950e")).Should(BeNil())
+			Expect(validateUniqueId("acme.portal_2")).Should(BeNil())
+		})
+		g.It("Should refuse what breaks build.gradle", func() {
+			Expect(validateUniqueId("")).ShouldNot(BeNil())
+			Expect(validateUniqueId("it's mine")).ShouldNot(BeNil())
+		})
+	})
+
+	g.Describe("Keeping the uniqueId of an existing project", func() {
+		var savedArgs CmdlineArgs
+		var savedProfile Profile
+		var root string
+		g.Before(func() {
+			savedArgs, savedProfile = args, profile
+			root, _ = ioutil.TempDir("", "workspace")
+			profile.WorkspaceRoot = root
+			args.dir = filepath.Join(root, "own")
+			existingUniqueId = "legacy id"
+		})
+		g.After(func() {
+			args, profile = savedArgs, savedProfile
+			existingUniqueId = ""
+			os.RemoveAll(root)
+		})
+		g.It("Should suggest and accept it even if it is invalid", func() {
+			Expect(suggestUniqueId()).Should(Equal("legacy id"))
+			Expect(checkUniqueId("legacy id")).Should(BeNil())
+		})
+		g.It("Should refuse another id in non-interactive mode", func() {
+			args.nonInteractive = true
+			Expect(checkUniqueId("0f8fad5b-d9cb-469f-a165-70867728950e")).ShouldNot(BeNil())
+		})
+	})
+
+	g.Describe("Reading uniqueIds", func() {
+		g.It("Should take the generated one, not the commented out template", func() {
+			content := "solution {\n    uniqueId 'generated'\n}\n/*\nsolution {\n    uniqueId 'template'\n}\n*/\n"
+			Expect(parseUniqueId(content)).Should(Equal("generated"))
+			Expect(parseUniqueId("solution {\n}\n")).Should(Equal(""))
+		})
+	})
+
+	g.Describe("Finding duplicate uniqueIds", func() {
+		g.It("Should look in the registry and the workspace", func() {
+			root, _ := ioutil.TempDir("", "workspace")
+			defer os.RemoveAll(root)
+			for _, dir := range []string{"own", "other", "unrelated", "other/build"} {
+				os.MkdirAll(filepath.Join(root, dir), 0755)
+			}
+			ioutil.WriteFile(filepath.Join(root, "own", "build.gradle"), []byte("uniqueId 'abc'\n"), 0644)
+			ioutil.WriteFile(filepath.Join(root, "other", "build.gradle"), []byte("uniqueId 'abc'\n"), 0644)
+			ioutil.WriteFile(filepath.Join(root, "other", "build", "build.gradle"), []byte("uniqueId 'abc'\n"), 0644)
+			ioutil.WriteFile(filepath.Join(root, "unrelated", "build.gradle"), []byte("uniqueId 'xyz'\n"), 0644)
+
+			registered := []RegisteredProject{{Directory: "/elsewhere", UniqueId: "abc"}, {Directory: filepath.Join(root, "own"), UniqueId: "abc"}}
+			users := findUniqueIdUsers("abc", filepath.Join(root, "own"), root, registered)
+			Expect(users).Should(Equal([]string{"/elsewhere", filepath.Join(root, "other")}))
+		})
+	})
+}
@@ -2,21 +2,63 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
 	"fmt"
 	"github.com/bgentry/speakeasy"
-	"github.com/franela/goreq"
 	"io"
+	"io/ioutil"
 	"os"
 	"os/exec"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
+// questionKey is the heading of a question like "CUSTOMERNAME:", lowercased.
+// It is used to look up answers given with -set.
+func questionKey(description string) string {
+	for _, line := range strings.Split(description, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasSuffix(line, ":") && !strings.Contains(line, " ") {
+			return strings.ToLower(strings.TrimSuffix(line, ":"))
+		}
+		return ""
+	}
+	return ""
+}
+
+// promptOutput is where prompts and menus are drawn; with -output=json stdout
+// is reserved for the summary.
+func promptOutput() io.Writer {
+	if args.output == "json" {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// stdinReader is shared by all questions, so lines typed ahead or piped in
+// are not lost in the buffer of a previous question.
+var stdinReader = bufio.NewReader(os.Stdin)
+
 func requestInput(value *string, description string) {
+	if args.nonInteractive {
+		if answer, found := args.answers[questionKey(description)]; found {
+			*value = answer
+		}
+		log.Debug("%s [%v]", strings.TrimSpace(strings.SplitN(strings.TrimSpace(description), "\n", 2)[0]), *value)
+		return
+	}
 	log.Warning(description)
 	log.Info("[%v]", *value)
-	fmt.Print("> ")
-	reader := bufio.NewReader(os.Stdin)
-	input, _, err := reader.ReadLine()
+	fmt.Fprint(promptOutput(), "> ")
+	input, _, err := stdinReader.ReadLine()
 	if err != nil {
 		log.Critical("Error: %v", err)
 		log.Fatal("No reason to go on. This ends now :(")
@@ -29,9 +71,31 @@ func requestInput(value *string, description string) {
 	}
 }
 
+// requestConfirmation answers no in non-interactive mode, nothing is changed
+// without being asked.
+func requestConfirmation(description string, defaultValue bool) bool {
+	if args.nonInteractive {
+		log.Debug("%s (y/n) [n]", description)
+		return false
+	}
+	answer := "n"
+	if defaultValue {
+		answer = "y"
+	}
+	requestInput(&answer, description+" (y/n)")
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
 func requestHiddenInput(value *string, description string) {
+	if args.nonInteractive {
+		if *value == "" {
+			log.Fatalf("%s but running non-interactively", strings.TrimSuffix(description, ":"))
+		}
+		return
+	}
 	log.Warning(description)
-	input, err := speakeasy.Ask("> ")
+	input, err := speakeasy.FAsk(promptOutput(), "> ")
 	if err != nil {
 		log.Critical("Error: %v", err)
 		log.Fatal("No reason to go on. This ends now :(")
@@ -43,23 +107,199 @@ func requestHiddenInput(value *string, description string) {
 	}
 }
 
+// compareVersions compares dotted versions numerically, e.g. 5.10 > 5.9.
+// Qualifiers like -SNAPSHOT are ignored. Returns -1, 0 or 1.
+func compareVersions(a string, b string) int {
+	partsA := versionParts(a)
+	partsB := versionParts(b)
+	for i := 0; i < len(partsA) || i < len(partsB); i++ {
+		var numberA, numberB int
+		if i < len(partsA) {
+			numberA = partsA[i]
+		}
+		if i < len(partsB) {
+			numberB = partsB[i]
+		}
+		if numberA < numberB {
+			return -1
+		}
+		if numberA > numberB {
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionParts(version string) []int {
+	version = strings.SplitN(strings.TrimSpace(version), "-", 2)[0]
+	parts := make([]int, 0)
+	for _, part := range strings.Split(version, ".") {
+		number, err := strconv.Atoi(part)
+		if err != nil {
+			break
+		}
+		parts = append(parts, number)
+	}
+	return parts
+}
+
+// detectToolVersion runs a tool found on PATH and extracts its version from
+// the output using the first group of pattern.
+func detectToolVersion(name string, pattern *regexp.Regexp, cmdArgs ...string) (string, error) {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", fmt.Errorf("%s not found on PATH", name)
+	}
+	output, err := exec.Command(path, cmdArgs...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%s failed: %s", path, err)
+	}
+	match := pattern.FindStringSubmatch(string(output))
+	if match == nil {
+		return "", fmt.Errorf("no version found in output of %s", path)
+	}
+	return match[1], nil
+}
+
+// readCsv reads records separated by commas or semicolons, whichever the
+// first line uses most, as spreadsheets export both. The first record is the header.
+func readCsv(input io.Reader) ([][]string, error) {
+	content, err := ioutil.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimPrefix(string(content), "\ufeff")
+	firstLine := strings.SplitN(text, "\n", 2)[0]
+
+	reader := csv.NewReader(strings.NewReader(text))
+	if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("file is empty")
+	}
+	return records, nil
+}
+
 // general make http request
 
-func executeCmd(cmdName string, cmdArgs ...string) {
-	cmd := exec.Command(cmdName, cmdArgs...)
+func executeCmd(ctx context.Context, cmdName string, cmdArgs ...string) {
+	_, err := executeCmdWithOutput(ctx, cmdName, cmdArgs...)
+	if err != nil {
+		log.Fatalf("%s\nThis ended abruptly.", err)
+	}
+}
+
+// executeCmdWithOutput streams the output of the command through the logging
+// backends and returns it together with the error instead of ending the program.
+// In quiet mode the output is shown on the console only if the command fails.
+// The command is interrupted when ctx is cancelled or -cmd-timeout has passed.
+func executeCmdWithOutput(ctx context.Context, cmdName string, cmdArgs ...string) (string, error) {
+	if args.cmdTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, args.cmdTimeout)
+		defer cancel()
+	}
+
+	runningCmds.Add(1)
+	defer runningCmds.Done()
+
+	cmd := exec.CommandContext(ctx, cmdName, cmdArgs...)
+	stopOnCancel(cmd)
+	workingDir, _ := os.Getwd()
 
 	log.Notice("> Executing: %s", strings.Join(cmd.Args, " "))
+	log.Info("Working directory: %s", workingDir)
 	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	output := &cmdOutput{}
+	cmd.Stdout = output.writer(false)
+	cmd.Stderr = output.writer(true)
 
+	start := time.Now()
 	err := cmd.Run()
+	output.close()
+	duration := time.Since(start)
+
+	exitCode := -1
+	if cmd.ProcessState != nil {
+		exitCode = cmd.ProcessState.ExitCode()
+	}
+	log.Info("Finished after %s with exit code %d: %s", duration.Round(time.Millisecond), exitCode, strings.Join(cmd.Args, " "))
+
+	if err != nil && args.quiet {
+		fmt.Fprint(os.Stderr, output.String())
+	}
+
+	record := CommandRecord{Command: strings.Join(cmd.Args, " "), WorkingDir: workingDir, ExitCode: exitCode, Seconds: duration.Seconds(), Status: "ok"}
 	if err != nil {
-		log.Fatalf("%s\nThis ended abruptly.", err)
+		record.Status = "failed"
+	}
+	switch ctx.Err() {
+	case context.Canceled:
+		record.Status = "cancelled"
+	case context.DeadlineExceeded:
+		record.Status = "timeout"
+		err = fmt.Errorf("%s did not finish within %s", cmdName, args.cmdTimeout)
+	}
+	recordCommand(record)
+	if ctx.Err() == context.Canceled {
+		abort()
 	}
+	return output.String(), err
+}
+
+// cmdOutput collects the output of a command and logs it line by line,
+// stdout as INFO and stderr as WARNING.
+type cmdOutput struct {
+	mutex   sync.Mutex
+	buffer  bytes.Buffer
+	writers []*io.PipeWriter
+	done    sync.WaitGroup
+}
+
+func (o *cmdOutput) writer(isStderr bool) io.Writer {
+	reader, writer := io.Pipe()
+	o.writers = append(o.writers, writer)
+	o.done.Add(1)
+	go func() {
+		defer o.done.Done()
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			line := scanner.Text()
+			o.mutex.Lock()
+			o.buffer.WriteString(line + "\n")
+			o.mutex.Unlock()
+			if isStderr {
+				cmdLog.Warning("%s", line)
+			} else {
+				cmdLog.Info("%s", line)
+			}
+		}
+		io.Copy(ioutil.Discard, reader)
+	}()
+	return writer
 }
 
-func downloadFromUrl(url string, targetDir string, fileName string, username string, password string) {
+func (o *cmdOutput) close() {
+	for _, writer := range o.writers {
+		writer.Close()
+	}
+	o.done.Wait()
+}
+
+func (o *cmdOutput) String() string {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	return o.buffer.String()
+}
+
+func downloadFromUrl(ctx context.Context, url string, targetDir string, fileName string, username string, password string) {
 	if targetDir == "" {
 		targetDir = "."
 	}
@@ -72,22 +312,23 @@ func downloadFromUrl(url string, targetDir string, fileName string, username str
 
 	log.Debug("Downloading from [%s] to [%s] using [%s:%s]", url, targetPath, username, Hidden(password))
 
-	res, err := goreq.Request{
-		Method:            "GET",
-		Uri:               url,
-		BasicAuthUsername: username,
-		BasicAuthPassword: password,
-	}.Do()
-	defer res.Body.Close()
+	res, err := httpRequest(ctx, "GET", url, username, password, "", nil)
 	if err != nil {
 		log.Panic(err)
 	}
+	defer res.Body.Close()
 
 	if res.StatusCode == 200 {
 		file, err := os.Create(targetPath)
 		if err != nil {
 			log.Panicf("Failed to create %s: %s", targetPath, err)
 		}
+		// an interrupted download leaves no partial file behind
+		removeCleanup := addCleanup(func() {
+			file.Close()
+			os.Remove(targetPath)
+		})
+		defer removeCleanup()
 		defer file.Close()
 
 		size, err := io.Copy(file, res.Body)
@@ -102,6 +343,36 @@ func downloadFromUrl(url string, targetDir string, fileName string, username str
 
 }
 
+// HttpStatusError is returned for responses other than 200 OK.
+type HttpStatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *HttpStatusError) Error() string {
+	return e.Status
+}
+
+func isNotFound(err error) bool {
+	statusError, ok := err.(*HttpStatusError)
+	return ok && statusError.StatusCode == 404
+}
+
+func readFromUrl(ctx context.Context, url string, username string, password string) ([]byte, error) {
+	log.Debug("Reading from [%s] using [%s:%s]", url, username, Hidden(password))
+
+	res, err := httpRequest(ctx, "GET", url, username, password, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return nil, &HttpStatusError{StatusCode: res.StatusCode, Status: res.Status}
+	}
+	return ioutil.ReadAll(res.Body)
+}
+
 /*
 func downloadGradleBuildTemplate() {
 
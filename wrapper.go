@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// the wrapper task supports --gradle-version since Gradle 2.4
+const minimumWrapperGradleVersion = "2.4"
+
+var gradleVersionPattern = regexp.MustCompile(`Gradle (\d+(\.\d+)*)`)
+
+func detectGradleVersion() (string, error) {
+	return detectToolVersion("gradle", gradleVersionPattern, "--version")
+}
+
+// requiredGradleVersion returns the wrapper version given with -gradle-version,
+// in the profile or in template.properties next to the build template, in
+// this order. An empty result means the local Gradle version is used.
+func requiredGradleVersion(ctx context.Context) string {
+	if args.gradleVersion != "" {
+		return args.gradleVersion
+	}
+	if profile.GradleVersion != "" {
+		return profile.GradleVersion
+	}
+
+	input, err := readFromUrl(ctx, templateUrl()+"template.properties", args.username, args.password)
+	if err != nil {
+		log.Debug("No template.properties shipped with the template (%s)", err)
+		return ""
+	}
+	gradleVersion, _ := parseProperties(string(input)).get("gradleVersion")
+	return gradleVersion
+}
+
+func gradleDistributionUrl(gradleVersion string) string {
+	return "https://services.gradle.org/distributions/gradle-" + gradleVersion + "-bin.zip"
+}
+
+func createGradleWrapper(ctx context.Context) {
+	localVersion, err := detectGradleVersion()
+	if err != nil {
+		log.Warning("Could not detect the local Gradle version: %s", err)
+	} else {
+		log.Notice("Local Gradle version: %s", localVersion)
+	}
+
+	wrapperVersion := requiredGradleVersion(ctx)
+	if wrapperVersion == "" {
+		executeCmd(ctx, "gradle", `-p`+args.dir+``, "wrapper")
+		return
+	}
+
+	if localVersion != "" && compareVersions(localVersion, minimumWrapperGradleVersion) < 0 {
+		log.Warning("Gradle %s is too old to generate a wrapper for Gradle %s, at least Gradle %s is needed.", localVersion, wrapperVersion, minimumWrapperGradleVersion)
+		log.Warning("The wrapper is generated with Gradle %s and pointed to Gradle %s afterwards.", localVersion, wrapperVersion)
+		executeCmd(ctx, "gradle", `-p`+args.dir+``, "wrapper")
+		pinWrapperDistribution(wrapperVersion)
+		return
+	}
+
+	executeCmd(ctx, "gradle", `-p`+args.dir+``, "wrapper", "--gradle-version", wrapperVersion)
+}
+
+func pinWrapperDistribution(gradleVersion string) {
+	path := args.dir + "/gradle/wrapper/gradle-wrapper.properties"
+	properties, err := readProperties(path)
+	if err != nil {
+		log.Critical("Could not read gradle-wrapper.properties: %s", err)
+		return
+	}
+	properties.set("distributionUrl", gradleDistributionUrl(gradleVersion))
+	if err := properties.write(path, 0644); err != nil {
+		log.Critical("Could not write to gradle-wrapper.properties: %s", err)
+	} else {
+		log.Notice("Wrapper points to Gradle %s", gradleVersion)
+	}
+}
+
+// gradleCommand prefers the project's wrapper over the Gradle on PATH so the
+// pinned version is used.
+func gradleCommand() string {
+	name := "gradlew"
+	if runtime.GOOS == "windows" {
+		name = "gradlew.bat"
+	}
+	path, err := filepath.Abs(filepath.Join(args.dir, name))
+	if err == nil {
+		if _, err = os.Stat(path); err == nil {
+			return path
+		}
+	}
+	log.Warning("No Gradle wrapper found, using gradle from PATH")
+	return "gradle"
+}
+
+func executeGradle(ctx context.Context, tasks ...string) {
+	executeCmd(ctx, gradleCommand(), append([]string{`-p` + args.dir + ``}, tasks...)...)
+}
+
+// verifyGradleBuild runs the verify task to prove the generated build.gradle
+// is valid before anything is committed.
+func verifyGradleBuild(ctx context.Context) {
+	log.Info("")
+	log.Info("> Verifying build.gradle using task [%s]", profile.VerifyTask)
+
+	output, err := executeCmdWithOutput(ctx, gradleCommand(), `-p`+args.dir+``, profile.VerifyTask)
+	if err != nil {
+		log.Critical("Gradle could not run [%s] on the generated build.gradle:", profile.VerifyTask)
+		log.Critical("%s", gradleErrorExcerpt(output))
+		log.Fatalf("%s\nThis ended abruptly.", err)
+	}
+	log.Notice("build.gradle verified")
+}
+
+// gradleErrorExcerpt returns the "What went wrong" part of Gradle's output or
+// the last lines if there is none.
+func gradleErrorExcerpt(output string) string {
+	lines := strings.Split(strings.Replace(output, "\r\n", "\n", -1), "\n")
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "* What went wrong:") {
+			continue
+		}
+		excerpt := make([]string, 0)
+		for _, next := range lines[i+1:] {
+			if strings.HasPrefix(next, "* Try:") {
+				break
+			}
+			excerpt = append(excerpt, next)
+		}
+		return strings.TrimSpace(strings.Join(excerpt, "\n"))
+	}
+
+	const tailLength = 20
+	if len(lines) > tailLength {
+		lines = lines[len(lines)-tailLength:]
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
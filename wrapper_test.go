@@ -0,0 +1,23 @@
+package main
+
+import (
+	. "github.com/franela/goblin"
+	. "github.com/onsi/gomega"
+	"testing"
+)
+
+func TestGradleErrorExcerpt(t *testing.T) {
+	g := Goblin(t)
+
+	RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	g.Describe("Extracting the error from Gradle's output", func() {
+		g.It("Should return the 'What went wrong' part", func() {
+			output := "FAILURE: Build failed with an exception.\n\n* Where:\nBuild file 'build.gradle' line: 12\n\n* What went wrong:\nPlugin with id 'solution' not found.\n\n* Try:\nRun with --stacktrace option\n"
+			Expect(gradleErrorExcerpt(output)).Should(Equal("Plugin with id 'solution' not found."))
+		})
+		g.It("Should fall back to the last lines", func() {
+			Expect(gradleErrorExcerpt("line 1\nline 2\n")).Should(Equal("line 1\nline 2"))
+		})
+	})
+}